@@ -0,0 +1,109 @@
+package fetcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sizer struct computes the batch size of fetchers configured with WithAdaptiveSize.
+// Until handlers report their throughput, the size starts at the minimum and doubles on every fetch
+// that finds more tasks queued than the current size. Once throughput is observed, the size becomes the number
+// of tasks that can be handled within the target batch time, smoothed over recent batches.
+// The size never exceeds the number of queued tasks and always stays within the configured bounds.
+type sizer struct {
+	mu      sync.Mutex
+	min     int
+	max     int
+	target  time.Duration
+	current int
+	perTask time.Duration
+}
+
+// newSizer function returns a sizer bounded by lower and upper, starting at the lower bound.
+// Bounds below one are raised to one, and an upper bound below the lower one is raised to the lower one.
+func newSizer(lower, upper int, target time.Duration) *sizer {
+	lower = max(lower, 1)
+	upper = max(upper, lower)
+
+	return &sizer{min: lower, max: upper, target: target, current: lower}
+}
+
+// next method returns the size of the next batch given the number of queued tasks, or a negative depth if unknown.
+func (s *sizer) next(depth int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.perTask > 0:
+		s.current = int(s.target / s.perTask)
+	case depth > int64(s.current):
+		s.current *= 2
+	}
+
+	s.current = min(max(s.current, s.min), s.max)
+
+	if depth >= 0 && depth < int64(s.current) {
+		return max(int(depth), s.min)
+	}
+
+	return s.current
+}
+
+// observe method records that tasks were handled within elapsed, updating the smoothed time per task.
+// Every new observation accounts for a fifth of the estimate, so a single slow batch does not collapse the size.
+func (s *sizer) observe(tasks int, elapsed time.Duration) {
+	if tasks <= 0 {
+		return
+	}
+
+	sample := elapsed / time.Duration(tasks)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.perTask == 0 {
+		s.perTask = max(sample, 1)
+		return
+	}
+
+	s.perTask = max(s.perTask+(sample-s.perTask)/5, 1)
+}
+
+// limit method returns the number of tasks the next fetch of keys may extract.
+// With adaptive sizing the queued tasks are counted with a pipelined LLEN of every key first;
+// if counting fails, the size is computed without the queue depth and the failure is logged.
+func (c *config[T]) limit(ctx context.Context, keys []string) int {
+	if c.adaptive == nil {
+		return c.size
+	}
+
+	depth := int64(0)
+
+	cmds, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.LLen(ctx, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.logger.Warn().Err(err).Strs("keys", keys).Msg("failed to measure queue depth")
+		return c.adaptive.next(-1)
+	}
+
+	for _, cmd := range cmds {
+		depth += cmd.(*redis.IntCmd).Val()
+	}
+
+	return c.adaptive.next(depth)
+}
+
+// observe method reports the throughput of the handlers to the adaptive sizer, if one is configured.
+func (c *config[T]) observe(tasks int, elapsed time.Duration) {
+	if c.adaptive != nil {
+		c.adaptive.observe(tasks, elapsed)
+	}
+}
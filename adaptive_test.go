@@ -0,0 +1,113 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSizer verifies how the adaptive batch size reacts to the queue depth and the observed throughput.
+func TestSizer(t *testing.T) {
+	t.Parallel()
+
+	// GrowsWithBacklog verifies that the size doubles while more tasks are queued and respects the upper bound.
+	t.Run("GrowsWithBacklog", func(t *testing.T) {
+		s := newSizer(10, 50, time.Second)
+
+		assert.Equal(t, 20, s.next(1000), "Expected the size to double with a backlog")
+		assert.Equal(t, 40, s.next(1000), "Expected the size to keep doubling with a backlog")
+		assert.Equal(t, 50, s.next(1000), "Expected the size to be capped by the upper bound")
+	})
+
+	// BoundedByDepth verifies that a short queue limits the batch without dropping below the lower bound.
+	t.Run("BoundedByDepth", func(t *testing.T) {
+		s := newSizer(5, 100, time.Second)
+
+		assert.Equal(t, 5, s.next(2), "Expected the lower bound for an almost empty queue")
+		assert.Equal(t, 5, s.next(-1), "Expected the current size when the depth is unknown")
+	})
+
+	// FollowsThroughput verifies that observed handling times determine the size and are smoothed.
+	t.Run("FollowsThroughput", func(t *testing.T) {
+		s := newSizer(1, 1000, time.Second)
+
+		// Ten tasks handled within a second give 100 milliseconds per task, so ten tasks fit the target.
+		s.observe(10, time.Second)
+		assert.Equal(t, 10, s.next(1000), "Expected the size to match the target batch time")
+
+		// A much faster batch only moves the estimate by a fifth of the difference.
+		s.observe(10, 500*time.Millisecond)
+		assert.Equal(t, 11, s.next(1000), "Expected the estimate to be smoothed")
+
+		assert.Equal(t, 3, s.next(3), "Expected the queue depth to still bound the size")
+	})
+
+	// InvalidBounds verifies that bounds are normalized.
+	t.Run("InvalidBounds", func(t *testing.T) {
+		s := newSizer(0, -5, time.Second)
+
+		assert.Equal(t, 1, s.next(0), "Expected the bounds to be raised to one")
+	})
+}
+
+// TestAdaptiveFetch verifies that a fetcher configured with adaptive sizing adapts the extracted batch.
+func TestAdaptiveFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	testKey := "fetcher.domain.com::adaptive_fetch"
+	assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+	transcoder := &JSONCodec[TestTask]{}
+	for i := 0; i < 100; i++ {
+		taskJSON, _ := transcoder.Encode(TestTask{ID: i})
+		assert.NoError(t, rdb.RPush(ctx, testKey, taskJSON).Err(), "Failed to push task into Redis")
+	}
+
+	fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithAdaptiveSize[TestTask](2, 50, 100*time.Millisecond))
+	assert.NoError(t, err, "Failed to create redis fetcher")
+
+	tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+	assert.NoError(t, fetchErr, "Failed to fetch tasks")
+	assert.Len(t, tasks, 4, "Expected the size to grow from the lower bound with a backlog")
+
+	// Slow handlers taking 50 milliseconds per task only fit two tasks into the target batch time.
+	fetcher.Observe(4, 200*time.Millisecond)
+
+	tasks, fetchErr = fetcher.Fetch(ctx, []string{testKey})
+	assert.NoError(t, fetchErr, "Failed to fetch tasks")
+	assert.Len(t, tasks, 2, "Expected the size to shrink for slow handlers")
+}
+
+// TestAdaptiveListsOnly verifies that fetchers of other data structures reject adaptive sizing instead of ignoring it.
+func TestAdaptiveListsOnly(t *testing.T) {
+	t.Parallel()
+
+	// The constructors never contact redis, so the client does not need a reachable server.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{os.Getenv("REDIS_ADDRESS")}})
+	defer rdb.Close()
+
+	adaptive := WithAdaptiveSize[TestTask](2, 50, 100*time.Millisecond)
+
+	_, delayedErr := NewDelayedFetcher[TestTask](WithClient[TestTask](rdb), adaptive)
+	assert.ErrorIs(t, delayedErr, ErrIncompatibleOptions, "Expected the delayed fetcher to reject adaptive sizing")
+
+	_, priorityErr := NewPriorityFetcher[TestTask](WithClient[TestTask](rdb), adaptive)
+	assert.ErrorIs(t, priorityErr, ErrIncompatibleOptions, "Expected the priority fetcher to reject adaptive sizing")
+
+	_, streamErr := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), adaptive)
+	assert.ErrorIs(t, streamErr, ErrIncompatibleOptions, "Expected the stream fetcher to reject adaptive sizing")
+}
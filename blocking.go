@@ -0,0 +1,98 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// wait method blocks until a task arrives in one of the lists identified by keys or the timeout expires.
+// It uses BLMPOP, which returns the first task together with up to limit tasks from the same list
+// in a single round trip. Servers older than redis 7 do not know BLMPOP, in which case
+// the method falls back to BLPOP followed by a regular extraction of the remaining batch,
+// which is also used when a byte budget is configured.
+// The method returns no entries and no error when the timeout expires without any task arriving.
+func (f *RedisFetcher[T]) wait(ctx context.Context, keys []string, limit int) ([]entry, error) {
+	// Without keys there is no list a task could arrive in.
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	timeout := f.blockTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+
+	// Blocking commands only accept whole seconds. Waiting less than a second is not possible,
+	// and waiting a full second could outlive the context, so the empty batch is returned instead.
+	timeout = timeout.Truncate(time.Second)
+	if timeout <= 0 {
+		return nil, ctx.Err()
+	}
+
+	// A blocking command may only wait on keys of a single slot in a cluster,
+	// so only the keys sharing the slot of the first, highest priority, key are watched.
+	if f.cluster {
+		keys = groupBySlot(keys)[0]
+	}
+
+	// BLMPOP cannot enforce a byte budget, so with a budget only the first task is taken by the blocking command.
+	if f.byteBudget > 0 {
+		return f.waitLegacy(ctx, keys, timeout, limit)
+	}
+
+	key, values, err := f.rdb.BLMPop(ctx, timeout, "left", int64(limit), keys...).Result()
+	if isUnknownCommand(err) {
+		return f.waitLegacy(ctx, keys, timeout, limit)
+	}
+
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]entry, 0, len(values))
+	for _, value := range values {
+		entries = append(entries, entry{key: key, raw: value})
+	}
+
+	return entries, nil
+}
+
+// waitLegacy method blocks with BLPOP, which returns a single task, and extracts the rest of the batch afterward.
+func (f *RedisFetcher[T]) waitLegacy(ctx context.Context, keys []string, timeout time.Duration, limit int) ([]entry, error) {
+	popped, err := f.rdb.BLPop(ctx, timeout, keys...).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []entry{{key: popped[0], raw: popped[1]}}
+	if limit == 1 {
+		return entries, nil
+	}
+
+	// The first task is already popped, so a failure of the follow-up extraction is only logged
+	// and the task is handed out on its own instead of being dropped together with the error.
+	rest, err := f.pop(ctx, keys, limit-1, entries)
+	if err != nil {
+		f.logger.Error().Err(err).Strs("keys", keys).Msg("failed to extract tasks after blocking pop")
+		return entries, nil
+	}
+
+	return append(entries, rest...), nil
+}
+
+// isUnknownCommand function reports whether err was returned by a server that does not implement the command.
+func isUnknownCommand(err error) bool {
+	return err != nil && strings.HasPrefix(strings.ToLower(err.Error()), "err unknown command")
+}
@@ -0,0 +1,94 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockingFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithBlockTimeout[TestTask](5*time.Second))
+	assert.NoError(t, err, "Failed to create redis fetcher")
+	assert.Equal(t, 5*time.Second, fetcher.blockTimeout, "Expected fetcher to use the provided block timeout")
+
+	// WaitsForTask verifies that Fetch blocks on empty lists until a task arrives, then returns the whole batch.
+	t.Run("WaitsForTask", func(t *testing.T) {
+		testKey := "fetcher.domain.com::blocking_wait"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		first, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		second, _ := transcoder.Encode(TestTask{ID: 2, Data: "task2"})
+
+		// Push the tasks shortly after the fetch has started blocking.
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			rdb.RPush(ctx, testKey, first, second)
+		}()
+
+		started := time.Now()
+		tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.GreaterOrEqual(t, time.Since(started), 200*time.Millisecond, "Expected Fetch to wait for the tasks")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "task1"}, {ID: 2, Data: "task2"}}, tasks, "Expected the whole batch after waking up")
+	})
+
+	// HonorsContextDeadline verifies that Fetch returns an empty batch once the context deadline is reached.
+	t.Run("HonorsContextDeadline", func(t *testing.T) {
+		testKey := "fetcher.domain.com::blocking_deadline"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		deadlineCtx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
+		defer cancel()
+
+		started := time.Now()
+		tasks, fetchErr := fetcher.Fetch(deadlineCtx, []string{testKey})
+		assert.NoError(t, fetchErr, "Expected an empty batch rather than an error")
+		assert.Empty(t, tasks, "Expected no tasks from an empty list")
+		assert.Less(t, time.Since(started), 1500*time.Millisecond, "Expected Fetch to return before the context deadline")
+	})
+
+	// IncompatibleOptions verifies that blocking mode cannot be combined with options that bypass direct pops.
+	t.Run("IncompatibleOptions", func(t *testing.T) {
+		_, scriptErr := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithBlockTimeout[TestTask](time.Second), WithScript[TestTask](testScript))
+		assert.ErrorIs(t, scriptErr, ErrIncompatibleOptions, "Expected ErrIncompatibleOptions when combining a script with blocking mode")
+
+		_, deadLetterErr := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithBlockTimeout[TestTask](time.Second), WithDeadLetter[TestTask]("dlq"))
+		assert.ErrorIs(t, deadLetterErr, ErrIncompatibleOptions, "Expected ErrIncompatibleOptions when combining a dead-letter list with blocking mode")
+	})
+
+	// SubSecondTimeout verifies that a timeout that would be rounded down to no blocking at all is rejected.
+	t.Run("SubSecondTimeout", func(t *testing.T) {
+		_, timeoutErr := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithBlockTimeout[TestTask](500*time.Millisecond))
+		assert.ErrorIs(t, timeoutErr, ErrInvalidBlockTimeout, "Expected ErrInvalidBlockTimeout for a sub-second block timeout")
+	})
+
+	// NoKeys verifies that a blocking fetch without keys returns an empty batch instead of waiting,
+	// including on a cluster, where the keys are grouped by slot before blocking.
+	t.Run("NoKeys", func(t *testing.T) {
+		clusterFetcher, clusterErr := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithBlockTimeout[TestTask](time.Second))
+		assert.NoError(t, clusterErr, "Failed to create redis fetcher")
+		clusterFetcher.cluster = true
+
+		tasks, fetchErr := clusterFetcher.Fetch(ctx, nil)
+		assert.NoError(t, fetchErr, "Expected an empty batch rather than an error")
+		assert.Empty(t, tasks, "Expected no tasks without keys")
+	})
+}
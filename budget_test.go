@@ -0,0 +1,133 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteBudget(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// task is a helper that encodes a task whose payload is padded to roughly the given size.
+	task := func(id, size int) string {
+		taskJSON, _ := transcoder.Encode(TestTask{ID: id, Data: strings.Repeat("x", size)})
+		return taskJSON
+	}
+
+	// StopsAtBudget verifies that extraction stops before the first task that would exceed the budget
+	// and leaves it at the head of its list.
+	t.Run("StopsAtBudget", func(t *testing.T) {
+		testKey := "fetcher.domain.com::budget_stop"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+		assert.NoError(t, rdb.RPush(ctx, testKey, task(1, 100), task(2, 100), task(3, 100), task(4, 10)).Err(), "Failed to push tasks into Redis")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithByteBudget[TestTask](300))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, tasks, 2, "Expected only the tasks fitting the budget")
+		assert.Equal(t, int64(2), rdb.LLen(ctx, testKey).Val(), "Expected the remaining tasks to stay in the list")
+		assert.Equal(t, task(3, 100), rdb.LIndex(ctx, testKey, 0).Val(), "Expected the first task beyond the budget to stay at the head")
+	})
+
+	// FirstTaskAlwaysReturned verifies that a single task larger than the budget is still returned on its own.
+	t.Run("FirstTaskAlwaysReturned", func(t *testing.T) {
+		testKey := "fetcher.domain.com::budget_first"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+		assert.NoError(t, rdb.RPush(ctx, testKey, task(1, 1000), task(2, 10)).Err(), "Failed to push tasks into Redis")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithByteBudget[TestTask](100))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, tasks, 1, "Expected the oversized first task to be returned alone")
+		assert.Equal(t, 1, tasks[0].ID, "Expected the oversized task to be returned")
+	})
+
+	// SharedAcrossSlots verifies that the budget is shared between slot groups of a cluster
+	// and between the lists of a round-robin extraction.
+	t.Run("SharedAcrossSlots", func(t *testing.T) {
+		keys := []string{"{budget_a}:lane", "{budget_b}:lane"}
+		assert.NoError(t, rdb.Del(ctx, keys...).Err(), "Failed to clean up Redis keys")
+
+		for _, key := range keys {
+			assert.NoError(t, rdb.RPush(ctx, key, task(1, 100), task(2, 100)).Err(), "Failed to push tasks into Redis")
+		}
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithByteBudget[TestTask](400), WithStrategy[TestTask](StrategyRoundRobin))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		fetcher.cluster = true
+
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, tasks, 3, "Expected the budget to be shared between slots")
+	})
+
+	// Blocking verifies that the tasks extracted after a blocking pop are bounded by the budget
+	// left by the task that woke the fetcher up, even when that task alone has used it up.
+	t.Run("Blocking", func(t *testing.T) {
+		testKey := "fetcher.domain.com::budget_blocking"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithByteBudget[TestTask](100), WithTaskSize[TestTask](10), WithBlockTimeout[TestTask](3*time.Second))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		// Push the tasks shortly after the fetch has started blocking.
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			rdb.RPush(ctx, testKey, task(1, 150), task(2, 150), task(3, 150), task(4, 150))
+		}()
+
+		tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, tasks, 1, "Expected only the oversized task that woke the fetcher up")
+		assert.Equal(t, int64(3), rdb.LLen(ctx, testKey).Val(), "Expected the tasks beyond the budget to stay in the list")
+	})
+
+	// Reliable verifies that the budget also bounds leased batches.
+	t.Run("Reliable", func(t *testing.T) {
+		testKey := "fetcher.domain.com::budget_reliable"
+		assert.NoError(t, rdb.Del(ctx, testKey, processingKey(testKey, "budget-worker"), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+		assert.NoError(t, rdb.RPush(ctx, testKey, task(1, 100), task(2, 100), task(3, 100)).Err(), "Failed to push tasks into Redis")
+
+		fetcher, err := NewReliableFetcher[TestTask](WithClient[TestTask](rdb), WithConsumer[TestTask]("budget-worker"), WithByteBudget[TestTask](250))
+		assert.NoError(t, err, "Failed to create reliable fetcher")
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, 2, "Expected only the tasks fitting the budget to be leased")
+		assert.Equal(t, int64(1), rdb.LLen(ctx, testKey).Val(), "Expected the remaining task to stay in the source list")
+	})
+
+	// ListsOnly verifies that fetchers of other data structures reject the budget instead of ignoring it.
+	t.Run("ListsOnly", func(t *testing.T) {
+		_, delayedErr := NewDelayedFetcher[TestTask](WithClient[TestTask](rdb), WithByteBudget[TestTask](250))
+		assert.ErrorIs(t, delayedErr, ErrIncompatibleOptions, "Expected the delayed fetcher to reject the budget")
+
+		_, priorityErr := NewPriorityFetcher[TestTask](WithClient[TestTask](rdb), WithByteBudget[TestTask](250))
+		assert.ErrorIs(t, priorityErr, ErrIncompatibleOptions, "Expected the priority fetcher to reject the budget")
+
+		_, streamErr := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithByteBudget[TestTask](250))
+		assert.ErrorIs(t, streamErr, ErrIncompatibleOptions, "Expected the stream fetcher to reject the budget")
+	})
+}
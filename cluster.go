@@ -0,0 +1,311 @@
+package fetcher
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slotCount defines the number of hash slots a redis cluster distributes its keys over.
+const slotCount = 16384
+
+// extraction struct describes how an extraction script is invoked for a group of source keys.
+// It allows the fetchers to share the logic that splits a fetch operation across cluster slots.
+type extraction struct {
+	// script holds the Lua script executed for every group of source keys.
+	script *redis.Script
+	// keys maps a group of source keys to the keys expected by the script.
+	keys func(group []string) []string
+	// args builds the arguments of the script for the given task limit.
+	args func(limit int) ([]interface{}, error)
+	// budgeted reports whether the script enforces the byte budget passed in the two arguments following args.
+	budgeted bool
+}
+
+// extract method runs the extraction against the source keys and returns up to limit extracted entries.
+// On a standalone or sentinel client all keys are passed to a single script invocation. On a cluster client
+// the keys are grouped by hash slot, as a script cannot touch keys of different slots. Groups are processed
+// one after another in the order of keys for the sequential strategy, so that priority lanes are honored,
+// and in parallel pipelined rounds for the round-robin strategy. The global limit is respected either way.
+func (c *config[T]) extract(ctx context.Context, keys []string, limit int, x extraction) ([]entry, error) {
+	return c.extractAfter(ctx, keys, limit, nil, x)
+}
+
+// extractAfter method runs the extraction like extract for a batch that already holds the prior entries.
+// Prior entries are not returned, but their payloads count toward the byte budget of the batch.
+func (c *config[T]) extractAfter(ctx context.Context, keys []string, limit int, prior []entry, x extraction) ([]entry, error) {
+	if !c.cluster {
+		return c.extractGroup(ctx, keys, limit, prior, x)
+	}
+
+	groups := groupBySlot(keys)
+	if len(groups) == 1 {
+		return c.extractGroup(ctx, groups[0], limit, prior, x)
+	}
+
+	var entries []entry
+	var err error
+
+	// A byte budget can only be shared between groups that are extracted one after another,
+	// so the parallel rounds are reserved for extractions without one.
+	if c.strategy == StrategyRoundRobin && (c.byteBudget <= 0 || !x.budgeted) {
+		entries, err = c.extractParallel(ctx, groups, limit, x)
+	} else {
+		entries, err = c.extractSequential(ctx, groups, limit, prior, x)
+	}
+
+	// Tasks extracted from other slots before the failure are already gone from their lists,
+	// so they are handed out rather than dropped, and the failure is only reported through the logger.
+	if err != nil && len(entries) > 0 {
+		c.logger.Error().Err(err).Strs("keys", keys).Msg("failed to extract tasks from some cluster slots")
+		return entries, nil
+	}
+
+	return entries, err
+}
+
+// extractGroup method runs the extraction script once for a group of source keys sharing a single slot.
+// The scripts read a remaining budget of zero as no budget at all, so an exhausted budget skips the script entirely.
+func (c *config[T]) extractGroup(ctx context.Context, group []string, limit int, prior []entry, x extraction) ([]entry, error) {
+	if c.exhausted(prior, x) {
+		return nil, nil
+	}
+
+	args, err := c.args(limit, prior, x)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := x.script.Run(ctx, c.rdb, x.keys(group), args...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseEntries(result, group), nil
+}
+
+// args method builds the arguments of the script for the given task limit.
+// Budgeted scripts additionally receive the bytes left in the budget of the batch, or zero without a budget,
+// followed by a flag allowing the first task of the batch to exceed the budget when nothing was extracted yet.
+func (c *config[T]) args(limit int, prior []entry, x extraction) ([]interface{}, error) {
+	args, err := x.args(limit)
+	if err != nil || !x.budgeted {
+		return args, err
+	}
+
+	if c.byteBudget <= 0 {
+		return append(args, 0, 1), nil
+	}
+
+	first := 0
+	if len(prior) == 0 {
+		first = 1
+	}
+
+	return append(args, c.byteBudget-payloadSize(prior), first), nil
+}
+
+// exhausted method reports whether the prior entries of a batch have used up the byte budget of a budgeted extraction.
+func (c *config[T]) exhausted(prior []entry, x extraction) bool {
+	return x.budgeted && c.byteBudget > 0 && len(prior) > 0 && payloadSize(prior) >= c.byteBudget
+}
+
+// payloadSize function returns the total size of the raw payloads of the entries in bytes.
+func payloadSize(entries []entry) int64 {
+	var size int64
+	for _, e := range entries {
+		size += int64(len(e.raw))
+	}
+
+	return size
+}
+
+// extractSequential method processes the slot groups one after another until the task limit is reached.
+// With a byte budget, every group only receives the bytes left by the groups before it.
+func (c *config[T]) extractSequential(ctx context.Context, groups [][]string, limit int, prior []entry, x extraction) ([]entry, error) {
+	entries := make([]entry, 0)
+	extracted := prior
+
+	for _, group := range groups {
+		remaining := limit - len(entries)
+		if remaining <= 0 {
+			break
+		}
+
+		if c.exhausted(extracted, x) {
+			break
+		}
+
+		batch, err := c.extractGroup(ctx, group, remaining, extracted, x)
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, batch...)
+		extracted = append(extracted[:len(extracted):len(extracted)], batch...)
+	}
+
+	return entries, nil
+}
+
+// extractParallel method processes the slot groups in rounds, splitting the remaining task limit evenly between them.
+// Every round is sent as a single pipeline, which the cluster client splits per node and executes in parallel.
+// Groups that returned fewer tasks than their share are exhausted and skipped in the following rounds.
+func (c *config[T]) extractParallel(ctx context.Context, groups [][]string, limit int, x extraction) ([]entry, error) {
+	entries := make([]entry, 0)
+	active := groups
+
+	for remaining := limit; remaining > 0 && len(active) > 0; remaining = limit - len(entries) {
+		limits := shares(remaining, len(active))
+		args := make([][]interface{}, len(active))
+		cmds := make([]*redis.Cmd, len(active))
+
+		for i := range active {
+			if limits[i] == 0 {
+				continue
+			}
+
+			var err error
+			if args[i], err = c.args(limits[i], nil, x); err != nil {
+				return entries, err
+			}
+		}
+
+		// Failures are inspected per command below, so the aggregated pipeline error is ignored.
+		_, _ = c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, group := range active {
+				if limits[i] > 0 {
+					cmds[i] = x.script.EvalSha(ctx, pipe, x.keys(group), args[i]...)
+				}
+			}
+
+			return nil
+		})
+
+		next := make([][]string, 0, len(active))
+
+		// Every command is inspected even after a failure, as the other groups have already been extracted.
+		var failure error
+
+		for i, group := range active {
+			// Groups without a share in this round keep their place for the next one.
+			if cmds[i] == nil {
+				next = append(next, group)
+				continue
+			}
+
+			result, err := cmds[i].Result()
+			// A node that has not cached the script yet rejects the call without running it,
+			// so it is safe to retry through Run, which loads the script on demand.
+			if redis.HasErrorPrefix(err, "NOSCRIPT") {
+				result, err = x.script.Run(ctx, c.rdb, x.keys(group), args[i]...).Result()
+			}
+
+			if err != nil {
+				if failure == nil {
+					failure = err
+				}
+
+				continue
+			}
+
+			extracted := parseEntries(result, group)
+			entries = append(entries, extracted...)
+
+			if len(extracted) == limits[i] {
+				next = append(next, group)
+			}
+		}
+
+		if failure != nil {
+			return entries, failure
+		}
+
+		active = next
+	}
+
+	return entries, nil
+}
+
+// shares function splits the remaining task limit evenly between n groups.
+// The remainder of the division is assigned to the first groups, so the shares always add up to remaining.
+func shares(remaining, n int) []int {
+	result := make([]int, n)
+
+	for i := range result {
+		result[i] = remaining / n
+		if i < remaining%n {
+			result[i]++
+		}
+	}
+
+	return result
+}
+
+// groupBySlot function groups the keys by their cluster hash slot.
+// Groups are ordered by the first occurrence of their slot, and keys keep their relative order within a group.
+func groupBySlot(keys []string) [][]string {
+	groups := make([][]string, 0)
+	index := make(map[int]int)
+
+	for _, key := range keys {
+		s := slot(key)
+
+		i, ok := index[s]
+		if !ok {
+			i = len(groups)
+			index[s] = i
+			groups = append(groups, nil)
+		}
+
+		groups[i] = append(groups[i], key)
+	}
+
+	return groups
+}
+
+// slot function computes the redis cluster hash slot of the key.
+// Only the hash tag is hashed when the key contains a non-empty one, as described by the cluster specification.
+func slot(key string) int {
+	if tag := hashTag(key); tag != "" {
+		key = tag
+	}
+
+	return int(crc16(key) % slotCount)
+}
+
+// hashTag function returns the hash tag of the key, or an empty string if the key has no non-empty hash tag.
+// The hash tag is the part of the key between the first '{' and the following '}'.
+func hashTag(key string) string {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return ""
+	}
+
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return ""
+	}
+
+	return key[start+1 : start+1+end]
+}
+
+// crc16 function computes the CRC16 XMODEM checksum used by redis cluster to map keys to slots.
+func crc16(data string) uint16 {
+	var crc uint16
+
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,124 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSlot is the table-driven test for the slot function.
+// It verifies that keys are mapped to the same hash slots as redis cluster does, including hash tags.
+func TestSlot(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		key      string
+		expected int
+	}{
+		{name: "Plain key", key: "foo", expected: 12182},
+		{name: "Checksum reference", key: "123456789", expected: 0x31C3},
+		{name: "Hash tag", key: "{user1000}.following", expected: slot("user1000")},
+		{name: "Empty hash tag", key: "foo{}{bar}", expected: int(crc16("foo{}{bar}") % slotCount)},
+		{name: "Unclosed hash tag", key: "foo{bar", expected: int(crc16("foo{bar") % slotCount)},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, slot(tt.key), "Slot mismatch for key %q", tt.key)
+		})
+	}
+}
+
+// TestGroupBySlot verifies that keys are grouped by slot in order of first occurrence.
+func TestGroupBySlot(t *testing.T) {
+	t.Parallel()
+
+	keys := []string{"{a}:high", "{b}:high", "{a}:low", "{b}:low"}
+
+	assert.Equal(t, [][]string{{"{a}:high", "{a}:low"}, {"{b}:high", "{b}:low"}}, groupBySlot(keys), "Unexpected slot groups")
+	assert.Equal(t, []int{4, 3, 3}, shares(10, 3), "Expected the remainder to be assigned to the first groups")
+	assert.Equal(t, []int{1, 0, 0}, shares(1, 3), "Expected groups without a share when the limit is small")
+}
+
+// TestClusterExtraction verifies the slot-grouped extraction used for cluster clients.
+// The Redis instance of the test environment is standalone, so the grouping is enabled manually;
+// every group is still extracted with a separate script invocation exactly as it would be in a cluster.
+func TestClusterExtraction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+	keys := []string{"{cluster_a}:lane", "{cluster_b}:lane", "{cluster_c}:lane"}
+
+	// push is a helper that fills every lane with the given number of tasks.
+	push := func(t *testing.T, count int) {
+		t.Helper()
+
+		for _, key := range keys {
+			assert.NoError(t, rdb.Del(ctx, key).Err(), "Failed to clean up Redis keys")
+
+			for i := 0; i < count; i++ {
+				taskJSON, _ := transcoder.Encode(TestTask{ID: i, Data: key})
+				assert.NoError(t, rdb.RPush(ctx, key, taskJSON).Err(), "Failed to push task into Redis")
+			}
+		}
+	}
+
+	// RoundRobin verifies that parallel rounds never extract more than the global task size.
+	t.Run("RoundRobin", func(t *testing.T) {
+		push(t, 3)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](7), WithStrategy[TestTask](StrategyRoundRobin))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		fetcher.cluster = true
+
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, tasks, 7, "Expected the global task size to be respected")
+
+		left := rdb.LLen(ctx, keys[0]).Val() + rdb.LLen(ctx, keys[1]).Val() + rdb.LLen(ctx, keys[2]).Val()
+		assert.Equal(t, int64(2), left, "Expected exactly the remaining tasks to stay in redis")
+	})
+
+	// Sequential verifies that slot groups are drained in priority order.
+	t.Run("Sequential", func(t *testing.T) {
+		push(t, 2)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](3))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		fetcher.cluster = true
+
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []string{keys[0], keys[0], keys[1]}, []string{tasks[0].Data, tasks[1].Data, tasks[2].Data}, "Expected lanes to be drained in order")
+	})
+
+	// Reliable verifies that leased tasks from several slots can be settled per slot.
+	t.Run("Reliable", func(t *testing.T) {
+		push(t, 1)
+
+		fetcher, err := NewReliableFetcher[TestTask](WithClient[TestTask](rdb), WithStrategy[TestTask](StrategyRoundRobin))
+		assert.NoError(t, err, "Failed to create reliable fetcher")
+		fetcher.cluster = true
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, 3, "Expected a task from every slot")
+
+		for _, delivery := range deliveries {
+			assert.NoError(t, delivery.Ack(ctx), "Failed to acknowledge delivery")
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package fetcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressionMagic holds the bytes that prefix every payload produced by the CompressionCodec.
+// The first byte is never emitted by JSON or valid UTF-8 text and is reserved in MessagePack, so payloads
+// of these formats written before compression was enabled are not mistaken for compressed ones.
+// Binary formats offer no such guarantee; in protobuf, for instance, the bytes form a valid field header.
+var compressionMagic = []byte{0xc1, 'z'}
+
+// maxDecompressedSize defines the largest payload any built-in algorithm decompresses, matching
+// the largest string redis can store. It keeps a small crafted payload from exhausting the memory of a consumer.
+const maxDecompressedSize = 512 << 20
+
+// Compression defines the interface of an algorithm used by the CompressionCodec.
+// Every algorithm is identified by a single byte stored in the payload header, so payloads compressed
+// with different algorithms can be decoded by the same codec. Implementations must be safe for concurrent use.
+type Compression interface {
+	// ID returns the byte identifying the algorithm in the payload header.
+	ID() byte
+	// Compress returns the compressed form of src.
+	Compress(src []byte) ([]byte, error)
+	// Decompress reverses Compress.
+	Decompress(src []byte) ([]byte, error)
+}
+
+// Gzip function returns the gzip compression algorithm, identified by the byte 1.
+// It offers wide compatibility at the cost of speed.
+func Gzip() Compression {
+	return gzipCompression{}
+}
+
+// Zstd function returns the zstandard compression algorithm, identified by the byte 2.
+// It offers the best compression ratio of the built-in algorithms at a good speed.
+func Zstd() Compression {
+	return zstdCompression{}
+}
+
+// Snappy function returns the snappy compression algorithm, identified by the byte 3.
+// It is the fastest of the built-in algorithms, suited to payloads decoded on hot paths.
+func Snappy() Compression {
+	return snappyCompression{}
+}
+
+// CompressionCodec is a transcoder that compresses the payloads of an inner transcoder.
+// Compressed payloads are prefixed with a header naming the algorithm, while payloads without the header
+// are passed to the inner transcoder unchanged. This allows compressed and uncompressed payloads to coexist
+// in the same list while producers migrate, and allows the algorithm to be changed later on.
+// Uncompressed payloads can only be told apart reliably for text formats and MessagePack, so lists holding
+// uncompressed payloads of binary formats such as protobuf should be drained before compression is enabled.
+// Payloads decompressing to more than 512 MiB are rejected with ErrPayloadTooLarge.
+type CompressionCodec[T any] struct {
+	inner       Transcoder[T]
+	compression Compression
+	known       map[byte]Compression
+}
+
+// NewCompressionCodec function returns a CompressionCodec wrapping the inner transcoder.
+// Payloads are encoded with the given algorithm, while every built-in algorithm, together with the given one,
+// is accepted when decoding. Encoding requires the inner transcoder to implement the Encoder interface.
+func NewCompressionCodec[T any](inner Transcoder[T], compression Compression) *CompressionCodec[T] {
+	known := map[byte]Compression{}
+
+	for _, c := range []Compression{Gzip(), Zstd(), Snappy(), compression} {
+		known[c.ID()] = c
+	}
+
+	return &CompressionCodec[T]{inner: inner, compression: compression, known: known}
+}
+
+// Encode method encodes the value with the inner transcoder and compresses the result.
+func (c *CompressionCodec[T]) Encode(src T) (string, error) {
+	bytes, err := c.EncodeBytes(src)
+
+	return string(bytes), err
+}
+
+// Decode method decompresses the payload when it carries a compression header and decodes it with the inner transcoder.
+func (c *CompressionCodec[T]) Decode(src string) (T, error) {
+	return c.DecodeBytes([]byte(src))
+}
+
+// EncodeBytes method encodes the value with the inner transcoder and returns the compressed payload with its header.
+// It returns ErrMissingEncoder when the inner transcoder cannot encode values.
+func (c *CompressionCodec[T]) EncodeBytes(src T) ([]byte, error) {
+	encoder, ok := c.inner.(Encoder[T])
+	if !ok {
+		return nil, ErrMissingEncoder
+	}
+
+	plain, err := encodeBytes(encoder, src)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := c.compression.Compress(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, 0, len(compressionMagic)+1+len(compressed))
+	payload = append(payload, compressionMagic...)
+	payload = append(payload, c.compression.ID())
+
+	return append(payload, compressed...), nil
+}
+
+// DecodeBytes method decompresses the payload when it carries a compression header and decodes it with the inner transcoder.
+// Payloads compressed with an algorithm that is not known to the codec are rejected with ErrUnknownCompression.
+func (c *CompressionCodec[T]) DecodeBytes(src []byte) (T, error) {
+	if !bytes.HasPrefix(src, compressionMagic) || len(src) == len(compressionMagic) {
+		return decodeBytes(c.inner, src)
+	}
+
+	id := src[len(compressionMagic)]
+
+	compression, ok := c.known[id]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %d", ErrUnknownCompression, id)
+	}
+
+	plain, err := compression.Decompress(src[len(compressionMagic)+1:])
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return decodeBytes(c.inner, plain)
+}
+
+// gzipCompression struct implements the gzip compression algorithm.
+type gzipCompression struct{}
+
+// ID method returns the byte identifying gzip in the payload header.
+func (gzipCompression) ID() byte {
+	return 1
+}
+
+// Compress method compresses src using gzip with the default compression level.
+func (gzipCompression) Compress(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress method decompresses a gzip stream.
+func (gzipCompression) Decompress(src []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	return readLimited(r, maxDecompressedSize)
+}
+
+// readLimited function reads r to the end, failing with ErrPayloadTooLarge as soon as more than limit bytes are read.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	plain, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(plain)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrPayloadTooLarge, limit)
+	}
+
+	return plain, nil
+}
+
+// zstdCoders returns the shared zstd encoder and decoder, which are safe for concurrent use
+// of EncodeAll and DecodeAll and expensive enough to be worth creating only once.
+var zstdCoders = sync.OnceValues(func() (*zstd.Encoder, *zstd.Decoder) {
+	encoder, _ := zstd.NewWriter(nil)
+	decoder, _ := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+
+	return encoder, decoder
+})
+
+// zstdCompression struct implements the zstandard compression algorithm.
+type zstdCompression struct{}
+
+// ID method returns the byte identifying zstandard in the payload header.
+func (zstdCompression) ID() byte {
+	return 2
+}
+
+// Compress method compresses src into a single zstandard frame.
+func (zstdCompression) Compress(src []byte) ([]byte, error) {
+	encoder, _ := zstdCoders()
+
+	return encoder.EncodeAll(src, nil), nil
+}
+
+// Decompress method decompresses zstandard frames.
+func (zstdCompression) Decompress(src []byte) ([]byte, error) {
+	_, decoder := zstdCoders()
+
+	plain, err := decoder.DecodeAll(src, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+		return nil, fmt.Errorf("%w: %w", ErrPayloadTooLarge, err)
+	}
+
+	return plain, err
+}
+
+// snappyCompression struct implements the snappy block compression algorithm.
+type snappyCompression struct{}
+
+// ID method returns the byte identifying snappy in the payload header.
+func (snappyCompression) ID() byte {
+	return 3
+}
+
+// Compress method compresses src into a snappy block.
+func (snappyCompression) Compress(src []byte) ([]byte, error) {
+	return snappy.Encode(nil, src), nil
+}
+
+// Decompress method decompresses a snappy block.
+func (snappyCompression) Decompress(src []byte) ([]byte, error) {
+	size, err := snappy.DecodedLen(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > maxDecompressedSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrPayloadTooLarge, size)
+	}
+
+	return snappy.Decode(nil, src)
+}
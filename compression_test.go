@@ -0,0 +1,97 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompressionCodec is the table-driven test for the round trip of CompressionCodec[T].
+// It verifies that every built-in algorithm reverses itself and actually shrinks repetitive payloads.
+func TestCompressionCodec(t *testing.T) {
+	task := TestTask{ID: 1, Data: strings.Repeat("payload", 100)}
+
+	cases := []struct {
+		name        string
+		compression Compression
+	}{
+		{name: "Gzip", compression: Gzip()},
+		{name: "Zstd", compression: Zstd()},
+		{name: "Snappy", compression: Snappy()},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := NewCompressionCodec[TestTask](NewJSONCodec[TestTask](), tt.compression)
+
+			encoded, err := codec.Encode(task)
+			assert.NoError(t, err, "Encode must succeed for valid Go values")
+			assert.True(t, strings.HasPrefix(encoded, string(compressionMagic)), "Expected the compression header")
+			assert.Equal(t, tt.compression.ID(), encoded[len(compressionMagic)], "Expected the algorithm in the header")
+			assert.Less(t, len(encoded), len(task.Data), "Expected the payload to be compressed")
+
+			decoded, err := codec.Decode(encoded)
+			assert.NoError(t, err, "Decode must succeed for its own output")
+			assert.Equal(t, task, decoded, "Expected the round trip to preserve the value")
+		})
+	}
+}
+
+// TestCompressionCodecMigration verifies that uncompressed payloads and payloads of other algorithms
+// are accepted, so that producers can be migrated gradually.
+func TestCompressionCodecMigration(t *testing.T) {
+	codec := NewCompressionCodec[TestTask](NewJSONCodec[TestTask](), Zstd())
+	task := TestTask{ID: 2, Data: "task2"}
+
+	decoded, err := codec.Decode(`{"id":2,"data":"task2"}`)
+	assert.NoError(t, err, "Expected uncompressed payloads to be decoded by the inner transcoder")
+	assert.Equal(t, task, decoded, "Unexpected task decoded from an uncompressed payload")
+
+	gzipped, err := NewCompressionCodec[TestTask](NewJSONCodec[TestTask](), Gzip()).Encode(task)
+	assert.NoError(t, err, "Encode must succeed for valid Go values")
+
+	decoded, err = codec.Decode(gzipped)
+	assert.NoError(t, err, "Expected payloads of other built-in algorithms to be decoded")
+	assert.Equal(t, task, decoded, "Unexpected task decoded from a gzip payload")
+
+	_, err = codec.Decode(string(compressionMagic) + "\x7fdata")
+	assert.ErrorIs(t, err, ErrUnknownCompression, "Expected unknown algorithms to be rejected")
+
+	_, err = codec.Decode(string(compressionMagic) + "\x02garbage")
+	assert.Error(t, err, "Expected corrupted payloads to be rejected")
+
+	_, err = NewCompressionCodec[TestTask](decodeOnlyTranscoder{}, Gzip()).Encode(task)
+	assert.ErrorIs(t, err, ErrMissingEncoder, "Expected encoding to require an inner encoder")
+}
+
+// TestCompressionLimit verifies that payloads decompressing beyond the size limit are rejected
+// before they are fully inflated. Headers announcing oversized content are crafted by hand,
+// as producing such payloads for real would take far too long.
+func TestCompressionLimit(t *testing.T) {
+	// Gzip verifies that reading stops once the limit is exceeded.
+	t.Run("Gzip", func(t *testing.T) {
+		plain, err := readLimited(strings.NewReader("payload"), 7)
+		assert.NoError(t, err, "Expected a payload within the limit to be read")
+		assert.Equal(t, "payload", string(plain), "Expected the whole payload")
+
+		_, err = readLimited(strings.NewReader("payload!"), 7)
+		assert.ErrorIs(t, err, ErrPayloadTooLarge, "Expected ErrPayloadTooLarge beyond the limit")
+	})
+
+	// Zstd verifies that a frame announcing more content than the limit is rejected.
+	t.Run("Zstd", func(t *testing.T) {
+		frame := []byte{0x28, 0xb5, 0x2f, 0xfd, 0xe0, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00}
+
+		_, err := Zstd().Decompress(frame)
+		assert.ErrorIs(t, err, ErrPayloadTooLarge, "Expected ErrPayloadTooLarge for an oversized frame")
+	})
+
+	// Snappy verifies that a block announcing more content than the limit is rejected.
+	t.Run("Snappy", func(t *testing.T) {
+		block := []byte{0x80, 0x80, 0x80, 0x80, 0x04, 0x00}
+
+		_, err := Snappy().Decompress(block)
+		assert.ErrorIs(t, err, ErrPayloadTooLarge, "Expected ErrPayloadTooLarge for an oversized block")
+	})
+}
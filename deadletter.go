@@ -0,0 +1,120 @@
+package fetcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+)
+
+// The script settleCommand is a Lua script that settles a batch of leased tasks in a single atomic step.
+// KEYS holds a triple of a processing list, a lease set and a hash of leased tasks for every task, and ARGV
+// the matching pair of the raw task and its lease identifier. Every task is removed from its processing list
+// and its lease is dropped.
+var settleCommand = redis.NewScript(`
+local settled = 0
+
+for i = 1, #KEYS, 3 do
+	local a = settled * 2 + 1
+	redis.call('ZREM', KEYS[i + 1], ARGV[a + 1])
+	redis.call('HDEL', KEYS[i + 2], ARGV[a + 1])
+	redis.call('LREM', KEYS[i], 1, ARGV[a])
+	settled = settled + 1
+end
+
+return settled
+`)
+
+// DeadLetter struct describes a task that could not be decoded and was moved to the dead-letter list.
+// Records are stored as JSON, so operators can inspect them and replay the payload to its source list.
+type DeadLetter struct {
+	// Payload holds the raw task exactly as it was stored in the source list.
+	// It is encoded as base64 in JSON, so binary payloads survive the round trip unchanged.
+	Payload []byte `json:"payload"`
+	// Error holds the message of the error returned by the transcoder.
+	Error string `json:"error"`
+	// Key holds the name of the source list the task was taken from.
+	Key string `json:"key"`
+	// Timestamp holds the moment the task was moved to the dead-letter list.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// settlement struct pairs a leased entry with its dead-letter record.
+// An empty record releases the entry, while a non-empty one moves it to the dead-letter list.
+type settlement struct {
+	entry  entry
+	record string
+}
+
+// newDeadLetterRecord function builds the JSON dead-letter record stored for an undecodable entry.
+func newDeadLetterRecord(e entry, cause error) (string, error) {
+	record, err := json.Marshal(DeadLetter{Payload: []byte(e.raw), Error: cause.Error(), Key: e.key, Timestamp: time.Now().UTC()})
+	if err != nil {
+		return "", err
+	}
+
+	return string(record), nil
+}
+
+// settle method releases or dead-letters the leased entries of the consumer.
+// Dead-letter records are appended to the dead-letter list first, in a separate step, as the list may live
+// in any slot of a cluster. The tasks are only removed from the processing lists once the records are stored,
+// so a failure in between may record a task twice, but never loses it.
+// Entries of a single hash slot are then settled in a single script invocation, so on a standalone client
+// the whole batch is settled atomically. Settling an empty batch is a no-op.
+func (c *config[T]) settle(ctx context.Context, settlements []settlement) error {
+	records := make([]interface{}, 0)
+	for _, s := range settlements {
+		if s.record != "" {
+			records = append(records, s.record)
+		}
+	}
+
+	if len(records) > 0 {
+		if err := c.rdb.RPush(ctx, c.deadLetter, records...).Err(); err != nil {
+			return err
+		}
+	}
+
+	if !c.cluster {
+		return c.settleGroup(ctx, settlements)
+	}
+
+	groups := make(map[int][]settlement)
+	order := make([]int, 0)
+
+	for _, s := range settlements {
+		k := slot(s.entry.key)
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+
+		groups[k] = append(groups[k], s)
+	}
+
+	for _, k := range order {
+		if err := c.settleGroup(ctx, groups[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settleGroup method removes the entries from the processing lists in a single invocation of the settle script.
+func (c *config[T]) settleGroup(ctx context.Context, settlements []settlement) error {
+	if len(settlements) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(settlements)*3)
+	args := make([]interface{}, 0, len(settlements)*2)
+
+	for _, s := range settlements {
+		keys = append(keys, processingKey(s.entry.key, c.consumer), leasesKey(s.entry.key), leasedKey(s.entry.key))
+		args = append(args, s.entry.raw, s.entry.meta)
+	}
+
+	return settleCommand.Run(ctx, c.rdb, keys, args...).Err()
+}
@@ -0,0 +1,110 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+	malformed := `{"id": 1, "data" "broken"`
+
+	// FetchRoutesUndecodable verifies that RedisFetcher moves undecodable tasks to the dead-letter list
+	// while returning the decodable ones and leaving nothing behind in the processing list.
+	t.Run("FetchRoutesUndecodable", func(t *testing.T) {
+		testKey := "fetcher.domain.com::dead_letter_fetch"
+		deadLetterKey := "fetcher.domain.com::dead_letter_fetch_dlq"
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithConsumer[TestTask]("dlq-worker"), WithDeadLetter[TestTask](deadLetterKey))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		// Remove leftovers of previous runs so that the test always starts from empty lists.
+		assert.NoError(t, rdb.Del(ctx, testKey, deadLetterKey, processingKey(testKey, "dlq-worker"), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+
+		valid, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		assert.NoError(t, rdb.RPush(ctx, testKey, valid, malformed).Err(), "Failed to push tasks into Redis")
+
+		tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "task1"}}, tasks, "Expected only the decodable task to be returned")
+
+		assert.Equal(t, int64(0), rdb.LLen(ctx, processingKey(testKey, "dlq-worker")).Val(), "Expected processing list to be empty")
+		assert.Equal(t, int64(0), rdb.ZCard(ctx, leasesKey(testKey)).Val(), "Expected all leases to be released")
+
+		records := rdb.LRange(ctx, deadLetterKey, 0, -1).Val()
+		assert.Len(t, records, 1, "Expected a single dead-letter record")
+
+		var record DeadLetter
+		assert.NoError(t, json.Unmarshal([]byte(records[0]), &record), "Failed to decode dead-letter record")
+		assert.Equal(t, malformed, string(record.Payload), "Expected the raw payload to be preserved")
+		assert.Equal(t, testKey, record.Key, "Expected the source key to be recorded")
+		assert.NotEmpty(t, record.Error, "Expected the decode error to be recorded")
+		assert.False(t, record.Timestamp.IsZero(), "Expected the timestamp to be recorded")
+	})
+
+	// ReliableRoutesUndecodable verifies that ReliableFetcher moves undecodable tasks to the dead-letter list
+	// while keeping the decodable ones leased until they are acknowledged.
+	t.Run("ReliableRoutesUndecodable", func(t *testing.T) {
+		testKey := "fetcher.domain.com::dead_letter_reliable"
+		deadLetterKey := "fetcher.domain.com::dead_letter_reliable_dlq"
+
+		fetcher, err := NewReliableFetcher[TestTask](WithClient[TestTask](rdb), WithConsumer[TestTask]("dlq-worker"), WithDeadLetter[TestTask](deadLetterKey))
+		assert.NoError(t, err, "Failed to create reliable fetcher")
+
+		assert.NoError(t, rdb.Del(ctx, testKey, deadLetterKey, processingKey(testKey, "dlq-worker"), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+
+		valid, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		assert.NoError(t, rdb.RPush(ctx, testKey, malformed, valid).Err(), "Failed to push tasks into Redis")
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, 1, "Expected only the decodable task to be delivered")
+
+		assert.Equal(t, []string{valid}, rdb.LRange(ctx, processingKey(testKey, "dlq-worker"), 0, -1).Val(), "Expected only the delivered task to stay leased")
+		assert.Equal(t, int64(1), rdb.LLen(ctx, deadLetterKey).Val(), "Expected a single dead-letter record")
+	})
+
+	// KeepsStagedOnDeadLetterFailure verifies that tasks stay in the processing list when the dead-letter list
+	// rejects their records, so the reaper can recover them instead of losing them.
+	t.Run("KeepsStagedOnDeadLetterFailure", func(t *testing.T) {
+		testKey := "fetcher.domain.com::dead_letter_failure"
+		deadLetterKey := "fetcher.domain.com::dead_letter_failure_dlq"
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithConsumer[TestTask]("dlq-worker"), WithDeadLetter[TestTask](deadLetterKey))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		assert.NoError(t, rdb.Del(ctx, testKey, deadLetterKey, processingKey(testKey, "dlq-worker"), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+
+		// A string stored under the dead-letter key makes every RPUSH to it fail.
+		assert.NoError(t, rdb.Set(ctx, deadLetterKey, "occupied", 0).Err(), "Failed to occupy the dead-letter key")
+		assert.NoError(t, rdb.RPush(ctx, testKey, malformed).Err(), "Failed to push tasks into Redis")
+
+		_, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.Error(t, fetchErr, "Expected the dead-letter failure to be returned")
+		assert.Equal(t, []string{malformed}, rdb.LRange(ctx, processingKey(testKey, "dlq-worker"), 0, -1).Val(), "Expected the task to stay staged")
+		assert.Equal(t, int64(1), rdb.ZCard(ctx, leasesKey(testKey)).Val(), "Expected the lease to be kept for the reaper")
+	})
+
+	// IncompatibleWithScript verifies that a dead-letter list cannot be combined with a custom extraction script.
+	t.Run("IncompatibleWithScript", func(t *testing.T) {
+		_, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithScript[TestTask](testScript), WithDeadLetter[TestTask]("dlq"))
+		assert.ErrorIs(t, err, ErrIncompatibleOptions, "Expected ErrIncompatibleOptions when combining a script with a dead-letter list")
+	})
+}
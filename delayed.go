@@ -0,0 +1,93 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The script delayedExtractCommand is a Lua script that pops due tasks from Redis sorted sets.
+// Every member of the sorted sets in KEYS is a task scored by its due time in Unix milliseconds.
+// The script removes up to ARGV[1] members whose score is not later than ARGV[2], visiting the sets
+// in the order of KEYS and the members in the order of their due time.
+// The script replies with {key, task} pairs so the caller knows which set every task was taken from.
+var delayedExtractCommand = redis.NewScript(`
+local max_tasks = tonumber(ARGV[1])
+local now = ARGV[2]
+local tasks = {}
+
+for _, key in ipairs(KEYS) do
+	local remaining = max_tasks - #tasks
+	if remaining <= 0 then
+		break
+	end
+
+	local due = redis.call('ZRANGEBYSCORE', key, '-inf', now, 'LIMIT', 0, remaining)
+	for _, task in ipairs(due) do
+		redis.call('ZREM', key, task)
+		table.insert(tasks, {key, task})
+	end
+end
+
+return tasks
+`)
+
+// DelayedFetcher struct provides a fetcher for tasks scheduled in the future.
+// Tasks are stored in Redis sorted sets scored by their due time in Unix milliseconds,
+// and only tasks whose due time has passed are extracted. Extraction and removal happen atomically,
+// so every due task is handed out exactly once, even with many concurrent fetchers.
+type DelayedFetcher[T any] struct {
+	config[T]
+}
+
+// NewDelayedFetcher function constructs a fully configured DelayedFetcher instance.
+// It accepts the same functional options as NewRedisFetcher, with WithClock replacing the source of the current time.
+// Options that only apply to lists, such as WithScript or WithStrategy, are ignored,
+// except for WithByteBudget and WithAdaptiveSize, which are rejected with ErrIncompatibleOptions.
+// The function returns an error when mandatory configuration is missing or options cannot be combined.
+func NewDelayedFetcher[T any](opts ...options[T]) (*DelayedFetcher[T], error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cfg.listOnly(); err != nil {
+		return nil, err
+	}
+
+	// Sorted sets are drained in the order of keys regardless of the configured strategy.
+	cfg.strategy = StrategySequential
+
+	return &DelayedFetcher[T]{config: cfg}, nil
+}
+
+// Fetch method retrieves up to the configured number of due tasks from the sorted sets identified by keys.
+// Tasks that cannot be decoded are skipped; use FetchResult to inspect them.
+// The method returns a slice of tasks of type T and an error if any occurred during the operation.
+func (f *DelayedFetcher[T]) Fetch(ctx context.Context, keys []string) ([]T, error) {
+	result, err := f.FetchResult(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Tasks, nil
+}
+
+// FetchResult method retrieves due tasks exactly like Fetch, but also reports the tasks that failed decoding.
+// The method returns the result of the operation and an error if the extraction itself failed.
+func (f *DelayedFetcher[T]) FetchResult(ctx context.Context, keys []string) (*Result[T], error) {
+	now := f.clock().UnixMilli()
+
+	entries, err := f.extract(ctx, keys, f.size, extraction{
+		script: delayedExtractCommand,
+		keys:   func(group []string) []string { return group },
+		args: func(limit int) ([]interface{}, error) {
+			return []interface{}{limit, now}, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return f.decode(entries), nil
+}
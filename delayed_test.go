@@ -0,0 +1,75 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelayedFetcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// Use a fixed clock so that the test controls which tasks are due.
+	now := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	fetcher, err := NewDelayedFetcher[TestTask](WithClient[TestTask](rdb), WithClock[TestTask](clock), WithTaskSize[TestTask](2))
+	assert.NoError(t, err, "Failed to create delayed fetcher")
+
+	// Ensure that the fetcher can be used wherever the generic interfaces are expected.
+	var _ ResultFetcher[TestTask] = fetcher
+
+	// schedule is a helper that adds a task to the sorted set with the given due time.
+	schedule := func(t *testing.T, key string, task TestTask, due time.Time) {
+		t.Helper()
+
+		taskJSON, _ := transcoder.Encode(task)
+		assert.NoError(t, rdb.ZAdd(ctx, key, redis.Z{Score: float64(due.UnixMilli()), Member: taskJSON}).Err(), "Failed to schedule task")
+	}
+
+	// OnlyDueTasks verifies that only tasks whose due time has passed are extracted, in due time order.
+	t.Run("OnlyDueTasks", func(t *testing.T) {
+		testKey := "fetcher.domain.com::delayed_due"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		schedule(t, testKey, TestTask{ID: 2, Data: "due"}, now.Add(-time.Second))
+		schedule(t, testKey, TestTask{ID: 1, Data: "overdue"}, now.Add(-time.Hour))
+		schedule(t, testKey, TestTask{ID: 3, Data: "future"}, now.Add(time.Minute))
+
+		tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "overdue"}, {ID: 2, Data: "due"}}, tasks, "Expected due tasks in due time order")
+		assert.Equal(t, int64(1), rdb.ZCard(ctx, testKey).Val(), "Expected the future task to stay scheduled")
+	})
+
+	// RespectsTaskSize verifies that no more than the configured number of tasks is extracted across keys.
+	t.Run("RespectsTaskSize", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::delayed_size_a", "fetcher.domain.com::delayed_size_b"}
+		assert.NoError(t, rdb.Del(ctx, keys...).Err(), "Failed to clean up Redis keys")
+
+		schedule(t, keys[0], TestTask{ID: 1, Data: "a"}, now.Add(-time.Second))
+		schedule(t, keys[1], TestTask{ID: 2, Data: "b"}, now.Add(-time.Second))
+		schedule(t, keys[1], TestTask{ID: 3, Data: "c"}, now.Add(-time.Second))
+
+		result, fetchErr := fetcher.FetchResult(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "a"}, {ID: 2, Data: "b"}}, result.Tasks, "Expected the batch to be limited to the task size")
+		assert.Equal(t, int64(1), rdb.ZCard(ctx, keys[1]).Val(), "Expected the remaining task to stay scheduled")
+	})
+}
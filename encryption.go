@@ -0,0 +1,149 @@
+package fetcher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+// encryptionVersion identifies the layout of the envelopes produced by the EncryptionCodec.
+const encryptionVersion = 1
+
+// EncryptionCodec is a transcoder that encrypts the payloads of an inner transcoder with AES-GCM.
+// Every envelope records the identifier of the key it was sealed with, so several keys can be active
+// during a rotation: new payloads are sealed with the primary key, while payloads sealed with any known
+// key can still be opened. Payloads that fail decryption are reported like any other decode failure.
+type EncryptionCodec[T any] struct {
+	inner   Transcoder[T]
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewEncryptionCodec function returns an EncryptionCodec wrapping the inner transcoder.
+// The keys map key identifiers to AES keys of 16, 24 or 32 bytes, and primary names the key used for encryption.
+// Identifiers are stored in every envelope and must be between 1 and 255 bytes long.
+// The function returns ErrInvalidKey when a key is invalid and ErrUnknownKey when the primary key is missing from the keys.
+func NewEncryptionCodec[T any](inner Transcoder[T], primary string, keys map[string][]byte) (*EncryptionCodec[T], error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+
+	for id, key := range keys {
+		if len(id) == 0 || len(id) > 255 {
+			return nil, fmt.Errorf("%w: identifier length %d", ErrInvalidKey, len(id))
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidKey, id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrInvalidKey, id, err)
+		}
+
+		aeads[id] = aead
+	}
+
+	return &EncryptionCodec[T]{inner: inner, primary: primary, keys: aeads}, nil
+}
+
+// Encode method encodes the value with the inner transcoder and encrypts the result.
+func (c *EncryptionCodec[T]) Encode(src T) (string, error) {
+	bytes, err := c.EncodeBytes(src)
+
+	return string(bytes), err
+}
+
+// Decode method decrypts the envelope and decodes the plaintext with the inner transcoder.
+func (c *EncryptionCodec[T]) Decode(src string) (T, error) {
+	return c.DecodeBytes([]byte(src))
+}
+
+// EncodeBytes method encodes the value with the inner transcoder and seals the result with the primary key.
+// The envelope consists of the layout version, the key identifier, a random nonce and the sealed plaintext.
+// The version and key identifier are authenticated as well, so they cannot be swapped without detection.
+// It returns ErrMissingEncoder when the inner transcoder cannot encode values.
+func (c *EncryptionCodec[T]) EncodeBytes(src T) ([]byte, error) {
+	encoder, ok := c.inner.(Encoder[T])
+	if !ok {
+		return nil, ErrMissingEncoder
+	}
+
+	plain, err := encodeBytes(encoder, src)
+	if err != nil {
+		return nil, err
+	}
+
+	aead := c.keys[c.primary]
+	header := appendKeyID([]byte{encryptionVersion}, c.primary)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	envelope := make([]byte, 0, len(header)+len(nonce)+len(plain)+aead.Overhead())
+	envelope = append(envelope, header...)
+	envelope = append(envelope, nonce...)
+
+	return aead.Seal(envelope, nonce, plain, header), nil
+}
+
+// DecodeBytes method opens the envelope with the key it names and decodes the plaintext with the inner transcoder.
+// Envelopes referencing a key that is not known are rejected with ErrUnknownKey, while malformed
+// or tampered envelopes are rejected with ErrDecryptionFailed.
+func (c *EncryptionCodec[T]) DecodeBytes(src []byte) (T, error) {
+	var zero T
+
+	if len(src) == 0 || src[0] != encryptionVersion {
+		return zero, fmt.Errorf("%w: unsupported envelope", ErrDecryptionFailed)
+	}
+
+	id, rest, ok := splitKeyID(src[1:])
+	if !ok {
+		return zero, fmt.Errorf("%w: truncated envelope", ErrDecryptionFailed)
+	}
+
+	aead, ok := c.keys[id]
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return zero, fmt.Errorf("%w: truncated envelope", ErrDecryptionFailed)
+	}
+
+	header := src[:len(src)-len(rest)]
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, sealed, header)
+	if err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+	}
+
+	return decodeBytes(c.inner, plain)
+}
+
+// appendKeyID function appends the key identifier to dst, prefixed with its length.
+func appendKeyID(dst []byte, id string) []byte {
+	dst = append(dst, byte(len(id)))
+
+	return append(dst, id...)
+}
+
+// splitKeyID function reads a length-prefixed key identifier from src and returns it together with the remaining bytes.
+// It reports false when src is too short to hold the identifier.
+func splitKeyID(src []byte) (string, []byte, bool) {
+	if len(src) == 0 || len(src) < 1+int(src[0]) {
+		return "", nil, false
+	}
+
+	n := int(src[0])
+
+	return string(src[1 : 1+n]), src[1+n:], true
+}
@@ -0,0 +1,116 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEncryptionCodec verifies the round trip of EncryptionCodec[T] and its behavior during a key rotation.
+func TestEncryptionCodec(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 16)
+	task := TestTask{ID: 1, Data: "secret"}
+
+	before, err := NewEncryptionCodec[TestTask](NewJSONCodec[TestTask](), "k1", map[string][]byte{"k1": oldKey})
+	assert.NoError(t, err, "Failed to create encryption codec")
+
+	after, err := NewEncryptionCodec[TestTask](NewJSONCodec[TestTask](), "k2", map[string][]byte{"k1": oldKey, "k2": newKey})
+	assert.NoError(t, err, "Failed to create encryption codec")
+
+	// RoundTrip verifies that the plaintext never appears in the envelope and is restored on decoding.
+	t.Run("RoundTrip", func(t *testing.T) {
+		encoded, encodeErr := after.Encode(task)
+		assert.NoError(t, encodeErr, "Encode must succeed for valid Go values")
+		assert.NotContains(t, encoded, "secret", "Expected the plaintext to be encrypted")
+
+		again, _ := after.Encode(task)
+		assert.NotEqual(t, encoded, again, "Expected every envelope to use a fresh nonce")
+
+		decoded, decodeErr := after.Decode(encoded)
+		assert.NoError(t, decodeErr, "Decode must succeed for its own output")
+		assert.Equal(t, task, decoded, "Expected the round trip to preserve the value")
+	})
+
+	// Rotation verifies that envelopes sealed with a retired key can still be opened,
+	// while envelopes sealed with a key that is not configured are rejected.
+	t.Run("Rotation", func(t *testing.T) {
+		old, _ := before.Encode(task)
+
+		decoded, decodeErr := after.Decode(old)
+		assert.NoError(t, decodeErr, "Expected envelopes of a retired key to be decoded")
+		assert.Equal(t, task, decoded, "Unexpected task decoded from an envelope of a retired key")
+
+		current, _ := after.Encode(task)
+
+		_, decodeErr = before.Decode(current)
+		assert.ErrorIs(t, decodeErr, ErrUnknownKey, "Expected envelopes of an unknown key to be rejected")
+	})
+
+	// Tampering verifies that modified and malformed envelopes fail decryption.
+	t.Run("Tampering", func(t *testing.T) {
+		encoded, _ := after.Encode(task)
+		tampered := []byte(encoded)
+		tampered[len(tampered)-1] ^= 0xff
+
+		_, decodeErr := after.Decode(string(tampered))
+		assert.ErrorIs(t, decodeErr, ErrDecryptionFailed, "Expected tampered envelopes to be rejected")
+
+		_, decodeErr = after.Decode(`{"id":1,"data":"secret"}`)
+		assert.ErrorIs(t, decodeErr, ErrDecryptionFailed, "Expected plaintext payloads to be rejected")
+
+		_, decodeErr = after.Decode(encoded[:5])
+		assert.ErrorIs(t, decodeErr, ErrDecryptionFailed, "Expected truncated envelopes to be rejected")
+	})
+
+	// InvalidKeys verifies that construction fails for a missing primary key and keys of an invalid size.
+	t.Run("InvalidKeys", func(t *testing.T) {
+		_, constructErr := NewEncryptionCodec[TestTask](NewJSONCodec[TestTask](), "missing", map[string][]byte{"k1": oldKey})
+		assert.ErrorIs(t, constructErr, ErrUnknownKey, "Expected a missing primary key to be rejected")
+
+		_, constructErr = NewEncryptionCodec[TestTask](NewJSONCodec[TestTask](), "k1", map[string][]byte{"k1": []byte("short")})
+		assert.ErrorIs(t, constructErr, ErrInvalidKey, "Expected keys of an invalid size to be rejected")
+
+		_, constructErr = NewEncryptionCodec[TestTask](NewJSONCodec[TestTask](), "", map[string][]byte{"": oldKey})
+		assert.ErrorIs(t, constructErr, ErrInvalidKey, "Expected empty key identifiers to be rejected")
+	})
+}
+
+// TestEncryptionCodecFetch verifies that envelopes which cannot be decrypted are reported
+// as decode failures by FetchResult, exactly like payloads rejected by the inner transcoder.
+func TestEncryptionCodecFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	testKey := "fetcher.domain.com::encryption_fetch"
+	assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+	codec, err := NewEncryptionCodec[TestTask](NewJSONCodec[TestTask](), "k1", map[string][]byte{"k1": bytes.Repeat([]byte{3}, 32)})
+	assert.NoError(t, err, "Failed to create encryption codec")
+
+	valid, _ := codec.Encode(TestTask{ID: 1, Data: "task1"})
+	assert.NoError(t, rdb.RPush(ctx, testKey, valid, strings.Replace(valid, "k1", "k9", 1)).Err(), "Failed to push tasks into Redis")
+
+	fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTranscoder[TestTask](codec))
+	assert.NoError(t, err, "Failed to create redis fetcher")
+
+	result, fetchErr := fetcher.FetchResult(ctx, []string{testKey})
+	assert.NoError(t, fetchErr, "Failed to fetch tasks")
+	assert.Equal(t, []TestTask{{ID: 1, Data: "task1"}}, result.Tasks, "Expected only the decryptable task")
+	assert.Len(t, result.Failures, 1, "Expected a single decode failure")
+	assert.ErrorIs(t, result.Failures[0], ErrUnknownKey, "Expected the failure to unwrap to the decryption error")
+}
@@ -0,0 +1,50 @@
+package fetcher
+
+import "time"
+
+// Envelope struct holds a task exactly as it was extracted from redis, together with its metadata.
+// It is returned by FetchRaw for callers that forward, audit or lazily decode payloads instead of
+// working with decoded values, and can decode the payload on demand with the transcoder of the fetcher.
+// FetchEnvelopes returns envelopes of decoded tasks, which can be returned to redis with Requeue.
+type Envelope[T any] struct {
+	// Raw holds the payload exactly as it was extracted from redis.
+	Raw string
+	// Key holds the name of the source key the task was taken from.
+	Key string
+	// Index holds the position of the task within the extracted batch.
+	Index int
+	// FetchedAt holds the moment the batch was extracted, as reported by the clock of the fetcher.
+	FetchedAt time.Time
+	// Task holds the decoded task for envelopes returned by FetchEnvelopes, and the zero value otherwise.
+	Task T
+
+	// err holds the reason the payload could not be read from the script reply.
+	err error
+	// transcoder holds the transcoder of the fetcher that extracted the task.
+	transcoder Transcoder[T]
+}
+
+// Decode method decodes the raw payload with the transcoder configured on the fetcher.
+// Payloads are decoded on every call, so callers that need the value more than once should keep it.
+// The method returns the decoded task and the error reported by the transcoder, if any.
+// Payloads that could not be read from the script reply report ErrUnexpectedReply instead.
+func (e Envelope[T]) Decode() (T, error) {
+	if e.err != nil {
+		var zero T
+		return zero, e.err
+	}
+
+	return e.transcoder.Decode(e.Raw)
+}
+
+// envelopes method wraps the extracted entries into envelopes sharing a single fetch timestamp.
+func (c *config[T]) envelopes(entries []entry) []Envelope[T] {
+	now := c.clock()
+	envelopes := make([]Envelope[T], 0, len(entries))
+
+	for i, e := range entries {
+		envelopes = append(envelopes, Envelope[T]{Raw: e.raw, Key: e.key, Index: i, FetchedAt: now, err: e.err, transcoder: c.transcoder})
+	}
+
+	return envelopes
+}
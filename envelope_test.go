@@ -0,0 +1,105 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// mixedReplyScript is a Lua script replying with a string, an integer, an error and a pair,
+// used to verify that elements which are not tasks are reported instead of dropped.
+var mixedReplyScript = redis.NewScript(`
+return {'{"id":1,"data":"task1"}', 42, redis.error_reply('boom'), {KEYS[1], '{"id":2,"data":"task2"}'}}
+`)
+
+func TestFetchRaw(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Envelopes verifies that raw payloads are returned untouched together with their metadata
+	// and that decoding happens only when requested.
+	t.Run("Envelopes", func(t *testing.T) {
+		testKey := "fetcher.domain.com::raw_envelopes"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		valid, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		assert.NoError(t, rdb.RPush(ctx, testKey, valid, "not json").Err(), "Failed to push tasks into Redis")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithClock[TestTask](func() time.Time { return now }))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		envelopes, fetchErr := fetcher.FetchRaw(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch raw tasks")
+		assert.Len(t, envelopes, 2, "Expected undecodable payloads to be returned as well")
+
+		assert.Equal(t, valid, envelopes[0].Raw, "Expected the raw payload to be returned")
+		assert.Equal(t, testKey, envelopes[0].Key, "Expected the source key to be returned")
+		assert.Equal(t, 1, envelopes[1].Index, "Expected the batch position to be returned")
+		assert.Equal(t, now, envelopes[1].FetchedAt, "Expected the fetch timestamp to come from the clock")
+
+		task, decodeErr := envelopes[0].Decode()
+		assert.NoError(t, decodeErr, "Expected the valid payload to be decoded on demand")
+		assert.Equal(t, TestTask{ID: 1, Data: "task1"}, task, "Unexpected decoded task")
+
+		_, decodeErr = envelopes[1].Decode()
+		assert.Error(t, decodeErr, "Expected the transcoder error to be returned on demand")
+	})
+
+	// DeadLetterPopsDirectly verifies that raw fetches pop tasks directly, without leasing them,
+	// even when a dead-letter list is configured.
+	t.Run("DeadLetterPopsDirectly", func(t *testing.T) {
+		testKey := "fetcher.domain.com::raw_dead_letter"
+		deadLetterKey := "fetcher.domain.com::raw_dead_letter_dlq"
+		assert.NoError(t, rdb.Del(ctx, testKey, deadLetterKey, processingKey(testKey, "raw-worker"), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+		assert.NoError(t, rdb.RPush(ctx, testKey, "not json").Err(), "Failed to push tasks into Redis")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithConsumer[TestTask]("raw-worker"), WithDeadLetter[TestTask](deadLetterKey))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		envelopes, fetchErr := fetcher.FetchRaw(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch raw tasks")
+		assert.Len(t, envelopes, 1, "Expected the raw task to be returned")
+		assert.Equal(t, int64(0), rdb.LLen(ctx, processingKey(testKey, "raw-worker")).Val(), "Expected the processing list to be empty")
+		assert.Equal(t, int64(0), rdb.ZCard(ctx, leasesKey(testKey)).Val(), "Expected no lease to be recorded")
+		assert.Equal(t, int64(0), rdb.LLen(ctx, deadLetterKey).Val(), "Expected nothing to be dead-lettered without decoding")
+	})
+
+	// UnexpectedReply verifies that reply elements which are not tasks are reported as failures,
+	// while integer tasks are converted to their decimal form.
+	t.Run("UnexpectedReply", func(t *testing.T) {
+		testKey := "fetcher.domain.com::raw_mixed_reply"
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithScript[TestTask](mixedReplyScript))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		envelopes, fetchErr := fetcher.FetchRaw(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch raw tasks")
+		assert.Len(t, envelopes, 4, "Expected every reply element to be returned")
+		assert.Equal(t, "42", envelopes[1].Raw, "Expected integers to be formatted in decimal")
+
+		_, decodeErr := envelopes[2].Decode()
+		assert.ErrorIs(t, decodeErr, ErrUnexpectedReply, "Expected unexpected elements to report ErrUnexpectedReply")
+
+		result, fetchErr := fetcher.FetchResult(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "task1"}, {ID: 2, Data: "task2"}}, result.Tasks, "Expected the decodable tasks")
+		assert.Len(t, result.Failures, 2, "Expected the integer and the error to be reported")
+		assert.ErrorIs(t, result.Failures[1], ErrUnexpectedReply, "Expected the error to be reported as unexpected")
+	})
+}
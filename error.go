@@ -5,3 +5,80 @@ import "errors"
 // ErrEmptyRedisClient is returned when attempting to create a fetcher without providing a Redis client.
 // The Redis client is mandatory for all fetcher operations — construction fails if it is missing.
 var ErrEmptyRedisClient = errors.New("redis client is empty")
+
+// ErrDeliveryNotFound is returned when a delivery is settled but its task is no longer in the processing list.
+// This happens when the delivery was already acknowledged or rejected, or when its lease was reclaimed.
+var ErrDeliveryNotFound = errors.New("delivery not found in processing list")
+
+// ErrIncompatibleOptions is returned when a fetcher is constructed with options that cannot be combined.
+// The returned error wraps this value together with a description of the conflicting options.
+var ErrIncompatibleOptions = errors.New("incompatible fetcher options")
+
+// ErrInvalidBlockTimeout is returned when a fetcher is constructed with a block timeout shorter than a second.
+// Redis blocking commands only wait for whole seconds, so such a timeout would never block at all.
+var ErrInvalidBlockTimeout = errors.New("block timeout must be at least one second")
+
+// ErrEmptyGroup is returned when attempting to create a stream fetcher without providing a consumer group.
+var ErrEmptyGroup = errors.New("consumer group is empty")
+
+// ErrMissingField is returned as the decode error of a stream entry that does not contain the configured field.
+var ErrMissingField = errors.New("stream entry does not contain the configured field")
+
+// ErrMissingEncoder is returned when attempting to create a publisher with a transcoder that cannot encode values.
+// The configured transcoder must implement the Encoder interface in addition to Transcoder.
+var ErrMissingEncoder = errors.New("transcoder does not implement encoder")
+
+// ErrInvalidMessageType is returned as the decode error of a protobuf transcoder whose message type is an interface.
+// Decoding needs a concrete message type, such as a generated message pointer, to allocate the result.
+var ErrInvalidMessageType = errors.New("message type cannot be instantiated")
+
+// ErrUnknownCompression is returned as the decode error of a compressed payload whose algorithm is not known.
+var ErrUnknownCompression = errors.New("unknown compression algorithm")
+
+// ErrPayloadTooLarge is returned as the decode error of a compressed payload that decompresses beyond the size limit.
+var ErrPayloadTooLarge = errors.New("decompressed payload is too large")
+
+// ErrUnknownKey is returned when a payload references a key that is not known to the transcoder,
+// or when a transcoder is constructed with a primary key that is missing from its key set.
+var ErrUnknownKey = errors.New("unknown key")
+
+// ErrInvalidKey is returned when a transcoder is constructed with a key it cannot use,
+// such as an identifier that does not fit the payload header or a secret of an unsupported size.
+var ErrInvalidKey = errors.New("invalid key")
+
+// ErrDecryptionFailed is returned as the decode error of an encrypted payload that cannot be decrypted.
+// It covers truncated payloads as well as payloads that fail authentication, for instance after tampering.
+var ErrDecryptionFailed = errors.New("payload decryption failed")
+
+// ErrInvalidSignature is returned as the decode error of a signed payload whose signature cannot be verified.
+// It covers unsigned and truncated payloads as well as payloads modified after they were signed.
+var ErrInvalidSignature = errors.New("invalid payload signature")
+
+// ErrUnknownVersion is returned as the decode error of a versioned payload that cannot be migrated to the current version.
+// This happens for payloads written by a newer producer and for versions without a registered migration.
+var ErrUnknownVersion = errors.New("unknown payload version")
+
+// ErrUnknownType is returned when a registry transcoder meets a type that is not registered,
+// either as the decode error of a payload with an unknown type name or when encoding an unregistered value.
+var ErrUnknownType = errors.New("unknown task type")
+
+// ErrTypeRegistered is returned by RegisterType when the type name or the concrete type is already registered.
+var ErrTypeRegistered = errors.New("task type already registered")
+
+// ErrInvalidType is returned by RegisterType for a type that is not assignable to the task type of the registry,
+// and when encoding a value whose dynamic type does not encode to a JSON object.
+var ErrInvalidType = errors.New("invalid task type")
+
+// ErrUnexpectedReply is returned as the decode error of an element of a script reply that is not a task.
+// Extraction scripts must reply with strings or integers, optionally paired with the key they were taken from.
+var ErrUnexpectedReply = errors.New("unexpected element in script reply")
+
+// ErrEmptyFetcher is returned when attempting to create a runner without providing a fetcher.
+var ErrEmptyFetcher = errors.New("fetcher is empty")
+
+// ErrEmptyHandler is returned when attempting to create a runner without providing a handler.
+var ErrEmptyHandler = errors.New("handler is empty")
+
+// ErrDrainExpired is reported through the error hook of a runner for every fetched task that was not handled
+// because the drain timeout expired before a worker became available.
+var ErrDrainExpired = errors.New("drain timeout expired before the task was handled")
@@ -1,6 +1,9 @@
 package fetcher
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Fetcher is a generic interface defining a contract for types that fetch data from a data source.
 // It specifies a single method, Fetch, which retrieves tasks of type T and returns them as a slice.
@@ -12,3 +15,34 @@ type Fetcher[T any] interface {
 	// The context parameter enables cancellation and timeout management, while keys specify the data source location.
 	Fetch(ctx context.Context, keys []string) ([]T, error)
 }
+
+// ResultFetcher is a generic interface extending Fetcher with a variant that reports decode failures.
+// It is implemented by fetchers that can tell the caller which extracted tasks could not be decoded,
+// while still satisfying the plain Fetcher contract for callers that are not interested in them.
+type ResultFetcher[T any] interface {
+	Fetcher[T]
+
+	// FetchResult retrieves tasks exactly like Fetch, but additionally returns the tasks that failed decoding.
+	// It returns an error only when the extraction itself fails; decode failures are part of the result.
+	FetchResult(ctx context.Context, keys []string) (*Result[T], error)
+}
+
+// RequeueFetcher is a generic interface extending Fetcher with the ability to return fetched tasks to redis.
+// It is implemented by fetchers that remember the raw payload and source key of every task they return,
+// which lets the Runner put the tasks it did not get to handle back at the head of their source lists on shutdown.
+type RequeueFetcher[T any] interface {
+	Fetcher[T]
+
+	// FetchEnvelopes retrieves tasks exactly like Fetch, but wraps every task in an envelope holding its raw payload.
+	FetchEnvelopes(ctx context.Context, keys []string) ([]Envelope[T], error)
+
+	// Requeue returns the tasks of the envelopes to the head of their source lists unchanged.
+	Requeue(ctx context.Context, envelopes []Envelope[T]) error
+}
+
+// Observer is an interface implemented by fetchers that adapt their batch size to the throughput of the handlers.
+// The Runner reports the handling time of every batch to fetchers implementing it.
+type Observer interface {
+	// Observe reports that the given number of fetched tasks was handled within elapsed.
+	Observe(tasks int, elapsed time.Duration)
+}
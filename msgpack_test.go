@@ -0,0 +1,73 @@
+package fetcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tagged is a test type annotated with msgpack tags that differ from its json tags.
+type Tagged struct {
+	Name  string `msgpack:"n" json:"name"`
+	Count int    `msgpack:"c,omitempty" json:"count"`
+}
+
+// TestMsgpackCodec is the table-driven test for the round trip of MsgpackCodec[T].
+// It verifies that values are reconstructed unchanged for primitive, pointer and struct types,
+// that json tags are honored for types without msgpack tags, and that msgpack tags take precedence.
+func TestMsgpackCodec(t *testing.T) {
+	cases := []struct {
+		name  string
+		check func(t *testing.T)
+	}{
+		{name: "Primitive int", check: func(t *testing.T) { roundTrip(t, NewMsgpackCodec[int](), 123) }},
+		{name: "Negative int", check: func(t *testing.T) { roundTrip(t, NewMsgpackCodec[int](), -70000) }},
+		{name: "String", check: func(t *testing.T) { roundTrip(t, NewMsgpackCodec[string](), "xai") }},
+		{name: "Nil pointer", check: func(t *testing.T) { roundTrip(t, NewMsgpackCodec[*string](), (*string)(nil)) }},
+		{name: "Struct with json tags", check: func(t *testing.T) { roundTrip(t, NewMsgpackCodec[User](), User{ID: 5, Name: "Bob", Age: intPtr(40)}) }},
+		{name: "Struct with msgpack tags", check: func(t *testing.T) { roundTrip(t, NewMsgpackCodec[Tagged](), Tagged{Name: "a", Count: 2}) }},
+		{name: "Map", check: func(t *testing.T) { roundTrip(t, NewMsgpackCodec[map[string]int](), map[string]int{"a": 1, "b": 2}) }},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, tt.check)
+	}
+}
+
+// roundTrip is a helper that encodes the value with the codec and verifies that decoding reverses it.
+func roundTrip[T any](t *testing.T, codec Codec[T], value T) {
+	t.Helper()
+
+	encoded, err := codec.Encode(value)
+	assert.NoError(t, err, "Encode must succeed for valid Go values")
+
+	decoded, err := codec.Decode(encoded)
+	assert.NoError(t, err, "Decode must succeed for its own output")
+	assert.Equal(t, value, decoded, "Expected the round trip to preserve the value")
+}
+
+// TestMsgpackCodecWireFormat verifies the exact bytes produced for tagged structs,
+// which is what producers written in other languages emit for the same map.
+func TestMsgpackCodecWireFormat(t *testing.T) {
+	codec := NewMsgpackCodec[Person]()
+
+	encoded, err := codec.EncodeBytes(Person{Name: "Al", Age: 3})
+	assert.NoError(t, err, "EncodeBytes must succeed for valid Go values")
+
+	// fixmap of two entries: "name" => "Al", "age" => 3, with omitempty dropping the email.
+	expected := []byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa2, 'A', 'l', 0xa3, 'a', 'g', 'e', 0x03}
+	assert.Equal(t, expected, encoded, "Unexpected MessagePack encoding")
+
+	decoded, err := codec.DecodeBytes(expected)
+	assert.NoError(t, err, "DecodeBytes must accept the foreign encoding")
+	assert.Equal(t, Person{Name: "Al", Age: 3}, decoded, "Expected the foreign encoding to be decoded")
+
+	// A producer that encodes the age as a 64-bit integer must be accepted as well.
+	wide := []byte{0x81, 0xa3, 'a', 'g', 'e', 0xd3, 0, 0, 0, 0, 0, 0, 0, 0x07}
+	decoded, err = codec.DecodeBytes(wide)
+	assert.NoError(t, err, "DecodeBytes must accept wide integers")
+	assert.Equal(t, Person{Age: 7}, decoded, "Expected the wide integer to be decoded")
+
+	_, err = codec.DecodeBytes([]byte{0xc1})
+	assert.Error(t, err, "DecodeBytes must reject reserved type codes")
+}
@@ -0,0 +1,322 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// popMode type describes how RedisFetcher removes tasks from the source lists.
+type popMode int32
+
+const (
+	// popUnknown marks a fetcher whose server has not been queried yet.
+	popUnknown popMode = iota
+	// popScript extracts tasks through the extraction script, which works on every server version.
+	popScript
+	// popCount extracts tasks with LPOP and a count, available since redis 6.2.
+	popCount
+	// popMulti extracts tasks with LMPOP across all keys at once, available since redis 7.0.
+	popMulti
+)
+
+// errUnknownVersion is reported when the server does not disclose a version the pop mode can be derived from.
+var errUnknownVersion = errors.New("server version is unknown")
+
+// mode method returns the pop mode of the fetcher, querying the server version with ctx on the first call.
+// A detection interrupted by ctx is retried on the next fetch, while any other outcome is kept for good.
+// The script is used until the mode is known, and whenever the server cannot be shown to support native pops.
+func (f *RedisFetcher[T]) mode(ctx context.Context) popMode {
+	if mode := popMode(f.native.Load()); mode != popUnknown {
+		return mode
+	}
+
+	mode, err := detectPopMode(ctx, f.rdb)
+	if err != nil && ctx.Err() != nil {
+		return popScript
+	}
+
+	if f.native.CompareAndSwap(int32(popUnknown), int32(mode)) && mode == popScript {
+		f.logger.Debug().Err(err).Msg("native list pops are not available, extracting tasks with the script")
+	}
+
+	return popMode(f.native.Load())
+}
+
+// detectPopMode function queries the server version and returns the fastest pop mode the server supports.
+// Servers older than redis 6.2 use the extraction script. Failing queries and servers that do not report
+// their version fall back to the script as well, but are also reported through the error.
+func detectPopMode(ctx context.Context, rdb redis.UniversalClient) (popMode, error) {
+	info, err := rdb.Info(ctx, "server").Result()
+	if err != nil {
+		return popScript, err
+	}
+
+	major, minor, ok := serverVersion(info)
+	switch {
+	case !ok:
+		return popScript, errUnknownVersion
+	case major >= 7:
+		return popMulti, nil
+	case major == 6 && minor >= 2:
+		return popCount, nil
+	default:
+		return popScript, nil
+	}
+}
+
+// serverVersion function extracts the major and minor version from the reply of INFO server.
+// It reports false when the reply does not carry a redis_version field that can be parsed.
+func serverVersion(info string) (int, int, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		version, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "redis_version:")
+		if !found {
+			continue
+		}
+
+		parts := strings.SplitN(version, ".", 3)
+		if len(parts) < 2 {
+			return 0, 0, false
+		}
+
+		major, majorErr := strconv.Atoi(parts[0])
+		minor, minorErr := strconv.Atoi(parts[1])
+		if majorErr != nil || minorErr != nil {
+			return 0, 0, false
+		}
+
+		return major, minor, true
+	}
+
+	return 0, 0, false
+}
+
+// popNative method removes up to limit tasks from the lists identified by keys with native list commands.
+// The sequential strategy drains the lists in the order of keys, using a single LMPOP per list when available.
+// The round-robin strategy deals the batch out between the lists exactly like the script and pops every share
+// with LPOP and a count, interleaving the replies so the batch keeps the order the script would produce.
+// Tasks popped before a failure are already gone from their lists, so they are returned and the failure is logged.
+func (f *RedisFetcher[T]) popNative(ctx context.Context, keys []string, limit int, mode popMode) ([]entry, error) {
+	var entries []entry
+	var err error
+
+	switch {
+	case f.strategy == StrategyRoundRobin:
+		entries, err = f.popRounds(ctx, keys, limit)
+	case mode == popMulti && !f.cluster:
+		entries, err = f.popMulti(ctx, keys, limit)
+	default:
+		entries, err = f.popEach(ctx, keys, limit)
+	}
+
+	if err != nil && len(entries) > 0 {
+		f.logger.Error().Err(err).Strs("keys", keys).Msg("failed to pop tasks from some lists")
+		return entries, nil
+	}
+
+	return entries, err
+}
+
+// popMulti method drains the lists in the order of keys with LMPOP, which pops from the first non-empty list.
+// Every call continues with the keys following the list that was popped from, until the limit is reached.
+// Servers that turn out not to know LMPOP are served by popEach instead.
+func (f *RedisFetcher[T]) popMulti(ctx context.Context, keys []string, limit int) ([]entry, error) {
+	entries := make([]entry, 0)
+
+	for remaining := keys; len(remaining) > 0 && len(entries) < limit; {
+		key, values, err := f.rdb.LMPop(ctx, "left", int64(limit-len(entries)), remaining...).Result()
+		if isUnknownCommand(err) {
+			rest, restErr := f.popEach(ctx, remaining, limit-len(entries))
+			return append(entries, rest...), restErr
+		}
+
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+
+		if err != nil {
+			return entries, err
+		}
+
+		for _, value := range values {
+			entries = append(entries, entry{key: key, raw: value})
+		}
+
+		for i, candidate := range remaining {
+			if candidate == key {
+				remaining = remaining[i+1:]
+				break
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// popEach method drains the lists one after another in the order of keys with LPOP and a count.
+func (f *RedisFetcher[T]) popEach(ctx context.Context, keys []string, limit int) ([]entry, error) {
+	entries := make([]entry, 0)
+
+	for _, key := range keys {
+		if len(entries) >= limit {
+			break
+		}
+
+		values, err := f.rdb.LPopCount(ctx, key, limit-len(entries)).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		if err != nil {
+			return entries, err
+		}
+
+		for _, value := range values {
+			entries = append(entries, entry{key: key, raw: value})
+		}
+	}
+
+	return entries, nil
+}
+
+// popRounds method pops tasks from the lists in the round-robin order of the extraction script.
+// The lengths of the lists are read in a single pipeline and the batch is dealt out one task per list in turn,
+// after which every list is popped by its share in a second pipeline and the replies are interleaved by round.
+// Lists drained by other consumers in the meantime return less than their share, and the shortfall is dealt
+// out again between the lists that returned their full share.
+func (f *RedisFetcher[T]) popRounds(ctx context.Context, keys []string, limit int) ([]entry, error) {
+	entries := make([]entry, 0)
+	active := keys
+
+	for len(entries) < limit && len(active) > 0 {
+		lengths := make([]*redis.IntCmd, len(active))
+
+		// Failures are inspected per command below, so the aggregated pipeline error is ignored.
+		_, _ = f.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range active {
+				lengths[i] = pipe.LLen(ctx, key)
+			}
+
+			return nil
+		})
+
+		available := make([]int64, len(active))
+		for i, cmd := range lengths {
+			length, err := cmd.Result()
+			if err != nil {
+				return entries, err
+			}
+
+			available[i] = length
+		}
+
+		counts := deal(available, limit-len(entries))
+		cmds := make([]*redis.StringSliceCmd, len(active))
+
+		_, _ = f.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range active {
+				if counts[i] > 0 {
+					cmds[i] = pipe.LPopCount(ctx, key, counts[i])
+				}
+			}
+
+			return nil
+		})
+
+		popped := make([][]string, len(active))
+		next := make([]string, 0, len(active))
+		shortfall := false
+
+		// Every command is inspected even after a failure, as the other lists have already been popped.
+		var failure error
+
+		for i, key := range active {
+			// Lists without a share were either empty or left out by the limit, and only the latter are kept.
+			if cmds[i] == nil {
+				if available[i] > 0 {
+					next = append(next, key)
+				}
+
+				continue
+			}
+
+			values, err := cmds[i].Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				if failure == nil {
+					failure = err
+				}
+
+				continue
+			}
+
+			popped[i] = values
+			if len(values) == counts[i] {
+				next = append(next, key)
+			} else {
+				shortfall = true
+			}
+		}
+
+		entries = append(entries, interleave(active, popped)...)
+
+		if failure != nil {
+			return entries, failure
+		}
+
+		// Without a shortfall the batch is either full or every list has been drained.
+		if !shortfall {
+			break
+		}
+
+		active = next
+	}
+
+	return entries, nil
+}
+
+// deal function splits limit between lists holding the available number of tasks, one task per list in turn,
+// skipping lists that have run out. The result matches the number of tasks the round-robin script takes from every list.
+func deal(available []int64, limit int) []int {
+	counts := make([]int, len(available))
+
+	for total, progressed := 0, true; total < limit && progressed; {
+		progressed = false
+
+		for i := range available {
+			if total == limit {
+				break
+			}
+
+			if int64(counts[i]) < available[i] {
+				counts[i]++
+				total++
+				progressed = true
+			}
+		}
+	}
+
+	return counts
+}
+
+// interleave function merges the tasks popped from every list into entries, taking one task per list in turn.
+func interleave(keys []string, popped [][]string) []entry {
+	entries := make([]entry, 0)
+
+	for round, progressed := 0, true; progressed; round++ {
+		progressed = false
+
+		for i, key := range keys {
+			if round < len(popped[i]) {
+				entries = append(entries, entry{key: key, raw: popped[i][round]})
+				progressed = true
+			}
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,280 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerVersion(t *testing.T) {
+	t.Parallel()
+
+	// Parses verifies that the version is read from the redis_version field among the other server fields.
+	t.Run("Parses", func(t *testing.T) {
+		major, minor, ok := serverVersion("# Server\r\nredis_version:6.2.14\r\nredis_mode:standalone\r\n")
+		assert.True(t, ok, "Expected the version to be parsed")
+		assert.Equal(t, 6, major, "Expected the major version to be parsed")
+		assert.Equal(t, 2, minor, "Expected the minor version to be parsed")
+	})
+
+	// Missing verifies that a reply without a version is reported, so the fetcher falls back to the script.
+	t.Run("Missing", func(t *testing.T) {
+		_, _, ok := serverVersion("# Server\r\nredis_mode:standalone\r\n")
+		assert.False(t, ok, "Expected a reply without a version to be rejected")
+
+		_, _, ok = serverVersion("redis_version:unknown\r\n")
+		assert.False(t, ok, "Expected a malformed version to be rejected")
+	})
+}
+
+func TestNativePop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// push is a helper that fills every list with the tasks identified by ids.
+	push := func(t *testing.T, keys []string, ids ...int) {
+		t.Helper()
+
+		assert.NoError(t, rdb.Del(ctx, keys...).Err(), "Failed to clean up Redis keys")
+
+		for _, key := range keys {
+			for _, id := range ids {
+				taskJSON, _ := transcoder.Encode(TestTask{ID: id})
+				assert.NoError(t, rdb.RPush(ctx, key, taskJSON).Err(), "Failed to push tasks into Redis")
+			}
+		}
+	}
+
+	// Sequential verifies that LPOP with a count drains the lists in the order of keys and honors the limit.
+	t.Run("Sequential", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::native_seq_high", "fetcher.domain.com::native_seq_low"}
+		push(t, keys, 1, 2, 3)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](4))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		fetcher.native.Store(int32(popCount))
+
+		envelopes, fetchErr := fetcher.FetchRaw(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, envelopes, 4, "Expected the limit to be honored")
+
+		for i, envelope := range envelopes[:3] {
+			assert.Equal(t, keys[0], envelope.Key, "Expected the first list to be drained first")
+			assert.Equal(t, i, envelope.Index, "Expected the tasks to keep their order")
+		}
+
+		assert.Equal(t, keys[1], envelopes[3].Key, "Expected the second list to fill the rest of the batch")
+		assert.Equal(t, int64(2), rdb.LLen(ctx, keys[1]).Val(), "Expected the remaining tasks to stay in the list")
+	})
+
+	// MultiFallback verifies that a server rejecting LMPOP is served with LPOP and a count instead.
+	t.Run("MultiFallback", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::native_multi_empty", "fetcher.domain.com::native_multi"}
+		push(t, keys[1:], 1, 2)
+		assert.NoError(t, rdb.Del(ctx, keys[0]).Err(), "Failed to clean up Redis keys")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		fetcher.native.Store(int32(popMulti))
+
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1}, {ID: 2}}, tasks, "Expected all tasks to be fetched in order")
+	})
+
+	// RoundRobin verifies that the native commands return the batch in exactly the order of the script,
+	// with exhausted lists leaving their share to the others.
+	t.Run("RoundRobin", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::native_rr_a", "fetcher.domain.com::native_rr_b", "fetcher.domain.com::native_rr_c"}
+
+		// fill is a helper that resets the lists to the same content before every fetch.
+		fill := func(t *testing.T) {
+			t.Helper()
+
+			push(t, keys[:1], 1, 2, 3, 4, 5)
+			push(t, keys[1:2], 6)
+			push(t, keys[2:], 7, 8, 9, 10)
+		}
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](7), WithStrategy[TestTask](StrategyRoundRobin))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		fill(t)
+		fetcher.native.Store(int32(popScript))
+		expected, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks through the script")
+		assert.Equal(t, []TestTask{{ID: 1}, {ID: 6}, {ID: 7}, {ID: 2}, {ID: 8}, {ID: 3}, {ID: 9}}, expected, "Expected the script to interleave the lists")
+
+		fill(t)
+		fetcher.native.Store(int32(popCount))
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, expected, tasks, "Expected the native commands to keep the order of the script")
+		assert.Equal(t, int64(2), rdb.LLen(ctx, keys[0]).Val(), "Expected the remaining tasks to stay in the list")
+	})
+
+	// ServerCommands verifies the native commands of the server itself rather than a forced mode, so it only runs
+	// against redis 6.2 and newer. LMPOP and the BLMPOP based blocking fetch are additionally checked on redis 7.
+	t.Run("ServerCommands", func(t *testing.T) {
+		mode, detectErr := detectPopMode(ctx, rdb)
+		if mode == popScript {
+			t.Skipf("server does not support native list pops: %v", detectErr)
+		}
+
+		keys := []string{"fetcher.domain.com::native_server_empty", "fetcher.domain.com::native_server_a", "fetcher.domain.com::native_server_b"}
+		assert.NoError(t, rdb.Del(ctx, keys[0]).Err(), "Failed to clean up Redis keys")
+		push(t, keys[1:2], 1, 2)
+		push(t, keys[2:], 3, 4)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](3))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		envelopes, fetchErr := fetcher.FetchRaw(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, int32(mode), fetcher.native.Load(), "Expected the detected mode to be used")
+		assert.Len(t, envelopes, 3, "Expected the limit to be honored")
+
+		for i, key := range []string{keys[1], keys[1], keys[2]} {
+			task, decodeErr := envelopes[i].Decode()
+			assert.NoError(t, decodeErr, "Failed to decode task")
+			assert.Equal(t, i+1, task.ID, "Expected the lists to be drained in order")
+			assert.Equal(t, key, envelopes[i].Key, "Expected every task to carry its source list")
+		}
+
+		if mode != popMulti {
+			return
+		}
+
+		blocking, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithBlockTimeout[TestTask](3*time.Second))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		// Drain the remaining task, then push a new pair shortly after the fetch has started blocking.
+		assert.NoError(t, rdb.Del(ctx, keys...).Err(), "Failed to clean up Redis keys")
+
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			first, _ := transcoder.Encode(TestTask{ID: 5})
+			second, _ := transcoder.Encode(TestTask{ID: 6})
+			rdb.RPush(ctx, keys[2], first, second)
+		}()
+
+		tasks, fetchErr := blocking.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 5}, {ID: 6}}, tasks, "Expected BLMPOP to return the whole batch")
+	})
+
+	// DetectsOnFirstFetch verifies that the server is only queried by the first fetch, with the context of the caller,
+	// and that a detection interrupted by a cancelled context is retried later.
+	t.Run("DetectsOnFirstFetch", func(t *testing.T) {
+		testKey := "fetcher.domain.com::native_detect"
+		push(t, []string{testKey}, 1)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		assert.Equal(t, int32(popUnknown), fetcher.native.Load(), "Expected no detection during construction")
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		assert.Equal(t, popScript, fetcher.mode(cancelled), "Expected the script while the mode is unknown")
+		assert.Equal(t, int32(popUnknown), fetcher.native.Load(), "Expected an interrupted detection to be retried")
+
+		tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1}}, tasks, "Expected the task to be fetched")
+
+		expected, _ := detectPopMode(ctx, rdb)
+		assert.Equal(t, int32(expected), fetcher.native.Load(), "Expected the detected mode to be kept")
+	})
+
+	// ScriptOnlyOptions verifies that options relying on the script never enable the native commands.
+	t.Run("ScriptOnlyOptions", func(t *testing.T) {
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithByteBudget[TestTask](100))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		assert.Equal(t, int32(popScript), fetcher.native.Load(), "Expected a byte budget to keep the script")
+
+		fetcher, err = NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithScript[TestTask](defaultExtractCommand))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+		assert.Equal(t, int32(popScript), fetcher.native.Load(), "Expected a custom script to be honored")
+	})
+}
+
+// BenchmarkFetch compares fetching batches through the extraction script with the native list commands.
+// It requires a redis server at REDIS_ADDRESS; the native modes are only meaningful on redis 6.2 and newer.
+func BenchmarkFetch(b *testing.B) {
+	ctx := context.Background()
+
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{os.Getenv("REDIS_ADDRESS")}})
+	defer rdb.Close()
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		b.Skipf("redis is not available: %v", err)
+	}
+
+	transcoder := &JSONCodec[TestTask]{}
+	keys := []string{"fetcher.domain.com::bench_a", "fetcher.domain.com::bench_b"}
+
+	payloads := make([]interface{}, 500)
+	for i := range payloads {
+		payloads[i], _ = transcoder.Encode(TestTask{ID: i, Data: "benchmark"})
+	}
+
+	modes := []struct {
+		name string
+		mode popMode
+	}{
+		{"Script", popScript},
+		{"LPopCount", popCount},
+		{"LMPop", popMulti},
+	}
+
+	strategies := []struct {
+		name     string
+		strategy Strategy
+	}{
+		{"Sequential", StrategySequential},
+		{"RoundRobin", StrategyRoundRobin},
+	}
+
+	for _, s := range strategies {
+		for _, m := range modes {
+			b.Run(fmt.Sprintf("%s/%s", s.name, m.name), func(b *testing.B) {
+				fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](len(payloads)), WithStrategy[TestTask](s.strategy))
+				if err != nil {
+					b.Fatal(err)
+				}
+				fetcher.native.Store(int32(m.mode))
+
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					for _, key := range keys {
+						if err = rdb.RPush(ctx, key, payloads[:len(payloads)/len(keys)]...).Err(); err != nil {
+							b.Fatal(err)
+						}
+					}
+					b.StartTimer()
+
+					if _, err = fetcher.FetchRaw(ctx, keys); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -1,16 +1,134 @@
 package fetcher
 
-import "github.com/redis/go-redis/v9"
+import (
+	"fmt"
+	"os"
+	"time"
 
-// options type defines the functional options pattern used to configure a RedisFetcher instance.
-type options[T any] func(c *RedisFetcher[T])
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// options type defines the functional options pattern used to configure the fetchers of this package.
+// Every option mutates the shared config, so the same options can be passed to any fetcher constructor.
+type options[T any] func(c *config[T])
+
+// config struct holds the settings shared by all redis-backed fetchers of this package.
+// It is populated by functional options during construction and is not modified afterward.
+// Fields that are irrelevant for a particular fetcher are simply ignored by it.
+type config[T any] struct {
+	transcoder        Transcoder[T]
+	rdb               redis.UniversalClient
+	extractCommand    *redis.Script
+	size              int
+	consumer          string
+	visibilityTimeout time.Duration
+	reapInterval      time.Duration
+	logger            zerolog.Logger
+	deadLetter        string
+	strategy          Strategy
+	cluster           bool
+	blockTimeout      time.Duration
+	clock             func() time.Time
+	order             Order
+	group             string
+	field             string
+	claimIdle         time.Duration
+	adaptive          *sizer
+	byteBudget        int64
+}
+
+// Strategy type selects how tasks are extracted when a fetch operation is given more than one key.
+type Strategy int
+
+const (
+	// StrategySequential drains the keys in the order they are passed, so earlier keys act as higher priority lanes.
+	// A later key is only read once every earlier key is empty. This is the default strategy.
+	StrategySequential Strategy = iota
+	// StrategyRoundRobin takes one task from every non-empty key in turn until the batch is full,
+	// so every key receives a fair share of the batch regardless of its position.
+	StrategyRoundRobin
+)
+
+// newConfig function applies the provided functional options and validates the resulting configuration.
+// It initializes default values for the optional settings shared by every fetcher of this package.
+// The function returns an error only when mandatory configuration is missing.
+func newConfig[T any](opts ...options[T]) (config[T], error) {
+	cfg := config[T]{logger: zerolog.Nop()}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.rdb == nil {
+		return cfg, ErrEmptyRedisClient
+	}
+
+	// Scripts may only touch keys of a single hash slot in a cluster, which requires splitting multi-key operations.
+	_, cfg.cluster = cfg.rdb.(*redis.ClusterClient)
+
+	if cfg.size <= 0 {
+		cfg.size = defaultTaskSize
+	}
+
+	if cfg.transcoder == nil {
+		cfg.transcoder = &JSONCodec[T]{}
+	}
+
+	if cfg.consumer == "" {
+		cfg.consumer = defaultConsumerName()
+	}
+
+	if cfg.visibilityTimeout <= 0 {
+		cfg.visibilityTimeout = defaultVisibilityTimeout
+	}
+
+	if cfg.reapInterval <= 0 {
+		cfg.reapInterval = defaultReapInterval
+	}
+
+	if cfg.clock == nil {
+		cfg.clock = time.Now
+	}
+
+	if cfg.field == "" {
+		cfg.field = defaultStreamField
+	}
+
+	return cfg, nil
+}
+
+// listOnly method returns an error when options that only the list fetchers honor have been configured.
+// The fetchers of other data structures use it to reject such options instead of silently ignoring them.
+func (c *config[T]) listOnly() error {
+	if c.byteBudget > 0 {
+		return fmt.Errorf("%w: byte budget only applies to list fetchers", ErrIncompatibleOptions)
+	}
+
+	if c.adaptive != nil {
+		return fmt.Errorf("%w: adaptive size only applies to list fetchers", ErrIncompatibleOptions)
+	}
+
+	return nil
+}
+
+// defaultConsumerName function builds a consumer name that is unique for the running process.
+// It combines the host name with the process id, which is stable for the lifetime of the process.
+func defaultConsumerName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
 
 // WithClient option assigns the redis client used by the RedisFetcher to communicate with redis.
 // This client is responsible for executing commands and Lua scripts against the redis instance.
 // Providing a valid redis client is required for the fetcher to function correctly.
 // The option stores the client reference directly on the RedisFetcher instance.
 func WithClient[T any](rdb redis.UniversalClient) options[T] {
-	return func(r *RedisFetcher[T]) {
+	return func(r *config[T]) {
 		r.rdb = rdb
 	}
 }
@@ -20,7 +138,7 @@ func WithClient[T any](rdb redis.UniversalClient) options[T] {
 // Providing a custom transcoder allows callers to control deserialization behavior.
 // The configured transcoder is stored on the RedisFetcher for later use during extraction.
 func WithTranscoder[T any](t Transcoder[T]) options[T] {
-	return func(r *RedisFetcher[T]) {
+	return func(r *config[T]) {
 		r.transcoder = t
 	}
 }
@@ -30,7 +148,7 @@ func WithTranscoder[T any](t Transcoder[T]) options[T] {
 // This option allows callers to customize extraction logic without modifying the fetcher itself.
 // The script reference is stored and later executed during task retrieval.
 func WithScript[T any](src *redis.Script) options[T] {
-	return func(r *RedisFetcher[T]) {
+	return func(r *config[T]) {
 		r.extractCommand = src
 	}
 }
@@ -40,7 +158,151 @@ func WithScript[T any](src *redis.Script) options[T] {
 // This option allows callers to control batch size based on workload or performance characteristics.
 // The configured size is stored on the RedisFetcher and used during extraction.
 func WithTaskSize[T any](size int) options[T] {
-	return func(r *RedisFetcher[T]) {
+	return func(r *config[T]) {
 		r.size = size
 	}
 }
+
+// WithConsumer option assigns the consumer name used by the ReliableFetcher to build its processing lists.
+// Every consumer owns a dedicated processing list per source key, so names must be unique per worker.
+// If this option is not provided, a name derived from the host name and the process id is used.
+func WithConsumer[T any](name string) options[T] {
+	return func(r *config[T]) {
+		r.consumer = name
+	}
+}
+
+// WithVisibilityTimeout option configures how long a task leased by the ReliableFetcher may stay unacknowledged.
+// Once the timeout has passed, the reaper considers the worker dead and returns the task to its source list.
+// If this option is not provided, the ReliableFetcher uses its internal default timeout of five minutes.
+func WithVisibilityTimeout[T any](timeout time.Duration) options[T] {
+	return func(r *config[T]) {
+		r.visibilityTimeout = timeout
+	}
+}
+
+// WithReapInterval option configures how often the background reaper looks for expired leases.
+// If this option is not provided, the reaper uses its internal default interval of thirty seconds.
+func WithReapInterval[T any](interval time.Duration) options[T] {
+	return func(r *config[T]) {
+		r.reapInterval = interval
+	}
+}
+
+// WithLogger option assigns the logger used to report failures that cannot be returned to the caller,
+// such as errors raised by background routines. If this option is not provided, nothing is logged.
+func WithLogger[T any](logger zerolog.Logger) options[T] {
+	return func(r *config[T]) {
+		r.logger = logger
+	}
+}
+
+// WithDeadLetter option configures the list that receives tasks which cannot be decoded by the transcoder.
+// Every rejected task is stored as a JSON encoded DeadLetter record holding the raw payload, the decode error,
+// the source key and a timestamp. The RedisFetcher stages tasks in the processing list of the consumer
+// while they are decoded, so a crash between extraction and dead-lettering leaves the payload in redis.
+// Staged tasks are only returned to their source lists by RedisFetcher.Reap or RedisFetcher.StartReaper,
+// so callers relying on this guarantee must run the reaper for the same keys.
+// The dead-letter list may live in any slot of a redis cluster, independently of the source lists.
+func WithDeadLetter[T any](key string) options[T] {
+	return func(r *config[T]) {
+		r.deadLetter = key
+	}
+}
+
+// WithStrategy option selects how tasks are extracted across the keys passed to a single fetch operation.
+// If this option is not provided, keys are drained sequentially in the order they are passed.
+// On a redis cluster only the round-robin strategy pipelines the keys of different slots in parallel.
+// The sequential strategy extracts one slot after another, costing a round trip per slot, because popping
+// from every slot at once would remove tasks from lower priority lists that the limit then has no room for.
+// Custom scripts configured through WithScript receive the strategy as their second argument.
+func WithStrategy[T any](strategy Strategy) options[T] {
+	return func(r *config[T]) {
+		r.strategy = strategy
+	}
+}
+
+// WithBlockTimeout option enables the blocking mode of the RedisFetcher.
+// In this mode Fetch does not return an empty batch right away when all lists are empty,
+// but waits up to the given timeout, or the deadline of its context if that is earlier, for a task to arrive.
+// Redis only supports whole seconds for blocking commands, so the timeout is rounded down to full seconds,
+// and timeouts shorter than a second are rejected by NewRedisFetcher with ErrInvalidBlockTimeout.
+// On a redis cluster a blocking command may only wait on keys of a single slot, so the fetcher only waits
+// for keys sharing the slot of the first key. Tasks arriving in other slots are picked up by the next fetch.
+// Blocking mode cannot be combined with WithScript or WithDeadLetter.
+func WithBlockTimeout[T any](timeout time.Duration) options[T] {
+	return func(r *config[T]) {
+		r.blockTimeout = timeout
+	}
+}
+
+// WithClock option replaces the clock used to compute lease deadlines and to decide which delayed tasks are due.
+// It exists primarily for tests, which can move time forward without waiting.
+// If this option is not provided, time.Now is used.
+func WithClock[T any](clock func() time.Time) options[T] {
+	return func(r *config[T]) {
+		r.clock = clock
+	}
+}
+
+// WithOrder option selects whether the PriorityFetcher pops the members with the lowest or the highest scores.
+// If this option is not provided, members with the lowest scores are popped first.
+func WithOrder[T any](order Order) options[T] {
+	return func(r *config[T]) {
+		r.order = order
+	}
+}
+
+// WithGroup option assigns the consumer group the StreamFetcher reads entries for.
+// The group is created on first use of every stream if it does not exist yet. This option is mandatory
+// for the StreamFetcher, while WithConsumer selects the consumer name within the group.
+func WithGroup[T any](group string) options[T] {
+	return func(r *config[T]) {
+		r.group = group
+	}
+}
+
+// WithField option selects the stream entry field holding the encoded task.
+// If this option is not provided, the StreamFetcher decodes the "payload" field.
+func WithField[T any](field string) options[T] {
+	return func(r *config[T]) {
+		r.field = field
+	}
+}
+
+// WithClaimIdle option enables claiming of stale pending entries by the StreamFetcher.
+// Before reading new entries, every fetch claims entries that were delivered to any consumer of the group
+// but stayed unacknowledged for at least the given duration, so entries of crashed consumers are retried.
+// If this option is not provided, pending entries are never claimed.
+func WithClaimIdle[T any](idle time.Duration) options[T] {
+	return func(r *config[T]) {
+		r.claimIdle = idle
+	}
+}
+
+// WithAdaptiveSize option replaces the fixed task size with a batch size adapted to the workload.
+// Every fetch extracts at most the number of tasks the handlers are able to process within target,
+// as reported through Observe, bounded by lower and upper and by the number of tasks queued in the lists.
+// Before any throughput is observed, the size starts at lower and grows while tasks are queued.
+// The Runner reports the throughput automatically. Measuring the queue depth costs an additional round trip per fetch.
+// Adaptive sizing only applies to list fetchers; NewDelayedFetcher, NewPriorityFetcher and NewStreamFetcher
+// reject it with ErrIncompatibleOptions.
+func WithAdaptiveSize[T any](lower, upper int, target time.Duration) options[T] {
+	return func(r *config[T]) {
+		r.adaptive = newSizer(lower, upper, target)
+	}
+}
+
+// WithByteBudget option caps the total size of the payloads extracted by a single fetch operation in bytes.
+// The budget is enforced inside the extraction script, which inspects every task before popping it and stops
+// at the first task that would exceed the budget, so no task beyond the budget is ever removed from its list.
+// The first task of a batch is always returned, even if it alone exceeds the budget. The task size still applies.
+// Custom scripts configured through WithScript receive the remaining budget and the first task flag
+// as their third and fourth arguments. If this option is not provided, batches are only limited by the task size.
+// The budget only applies to list fetchers; NewDelayedFetcher, NewPriorityFetcher and NewStreamFetcher
+// reject it with ErrIncompatibleOptions.
+func WithByteBudget[T any](bytes int64) options[T] {
+	return func(r *config[T]) {
+		r.byteBudget = bytes
+	}
+}
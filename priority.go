@@ -0,0 +1,149 @@
+package fetcher
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The script priorityExtractCommand is a Lua script that pops the best scored tasks from Redis sorted sets.
+// It uses the command ARGV[2], either ZPOPMIN or ZPOPMAX, to pop up to ARGV[1] members, visiting the sets
+// in the order of KEYS. Every set is popped with a single command, so the script stays short even for large batches.
+// The script replies with {key, task, score} triples so the caller can expose the score of every task.
+var priorityExtractCommand = redis.NewScript(`
+local max_tasks = tonumber(ARGV[1])
+local command = ARGV[2]
+local tasks = {}
+
+for _, key in ipairs(KEYS) do
+	local remaining = max_tasks - #tasks
+	if remaining <= 0 then
+		break
+	end
+
+	local popped = redis.call(command, key, remaining)
+	for i = 1, #popped, 2 do
+		table.insert(tasks, {key, popped[i], popped[i + 1]})
+	end
+end
+
+return tasks
+`)
+
+// Order type selects which end of a sorted set the PriorityFetcher pops tasks from.
+type Order int
+
+const (
+	// LowestFirst pops the members with the lowest scores first using ZPOPMIN. This is the default order.
+	LowestFirst Order = iota
+	// HighestFirst pops the members with the highest scores first using ZPOPMAX.
+	HighestFirst
+)
+
+// command method returns the redis command implementing the order.
+func (o Order) command() string {
+	if o == HighestFirst {
+		return "ZPOPMAX"
+	}
+
+	return "ZPOPMIN"
+}
+
+// PriorityFetcher struct provides a fetcher for tasks stored in Redis sorted sets scored by priority.
+// Every fetch atomically pops the best scored tasks, either the lowest or the highest ones depending
+// on the configured order, which makes hand-written extraction scripts for priority queues unnecessary.
+type PriorityFetcher[T any] struct {
+	config[T]
+}
+
+// Scored struct pairs a decoded task with the score it had in its sorted set.
+type Scored[T any] struct {
+	// Task holds the decoded value of the task.
+	Task T
+	// Score holds the score of the task at the moment it was popped.
+	Score float64
+	// Key holds the name of the sorted set the task was taken from.
+	Key string
+}
+
+// NewPriorityFetcher function constructs a fully configured PriorityFetcher instance.
+// It accepts the same functional options as NewRedisFetcher, with WithOrder selecting which tasks are popped first.
+// Options that only apply to lists, such as WithScript or WithStrategy, are ignored,
+// except for WithByteBudget and WithAdaptiveSize, which are rejected with ErrIncompatibleOptions.
+// The function returns an error when mandatory configuration is missing or options cannot be combined.
+func NewPriorityFetcher[T any](opts ...options[T]) (*PriorityFetcher[T], error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cfg.listOnly(); err != nil {
+		return nil, err
+	}
+
+	// Sorted sets are drained in the order of keys regardless of the configured strategy.
+	cfg.strategy = StrategySequential
+
+	return &PriorityFetcher[T]{config: cfg}, nil
+}
+
+// Fetch method pops up to the configured number of tasks from the sorted sets identified by keys.
+// Earlier keys are drained first, so priorities are only compared between members of the same set.
+// Tasks that cannot be decoded are skipped; use FetchResult to inspect them.
+// The method returns a slice of tasks of type T and an error if any occurred during the operation.
+func (f *PriorityFetcher[T]) Fetch(ctx context.Context, keys []string) ([]T, error) {
+	result, err := f.FetchResult(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Tasks, nil
+}
+
+// FetchResult method pops tasks exactly like Fetch, but also reports the tasks that failed decoding.
+// The method returns the result of the operation and an error if the extraction itself failed.
+func (f *PriorityFetcher[T]) FetchResult(ctx context.Context, keys []string) (*Result[T], error) {
+	entries, err := f.pop(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.decode(entries), nil
+}
+
+// FetchScored method pops tasks exactly like Fetch, but returns every task together with its score and source key.
+// Tasks that cannot be decoded are skipped and reported through the configured logger.
+// The method returns the scored tasks in the order they were popped and an error if the operation failed.
+func (f *PriorityFetcher[T]) FetchScored(ctx context.Context, keys []string) ([]Scored[T], error) {
+	entries, err := f.pop(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Scored[T], 0, len(entries))
+
+	for i, e := range entries {
+		task, decodeErr := f.decodeEntry(e)
+		if decodeErr != nil {
+			f.logFailure(DecodeFailure{Raw: e.raw, Key: e.key, Index: i, Err: decodeErr})
+			continue
+		}
+
+		score, _ := strconv.ParseFloat(e.meta, 64)
+		tasks = append(tasks, Scored[T]{Task: task, Score: score, Key: e.key})
+	}
+
+	return tasks, nil
+}
+
+// pop method removes up to the configured number of tasks from the sorted sets identified by keys.
+func (f *PriorityFetcher[T]) pop(ctx context.Context, keys []string) ([]entry, error) {
+	return f.extract(ctx, keys, f.size, extraction{
+		script: priorityExtractCommand,
+		keys:   func(group []string) []string { return group },
+		args: func(limit int) ([]interface{}, error) {
+			return []interface{}{limit, f.order.command()}, nil
+		},
+	})
+}
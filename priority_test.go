@@ -0,0 +1,72 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityFetcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// fill is a helper that stores tasks in the sorted set using their identifier as score.
+	fill := func(t *testing.T, key string, tasks ...TestTask) {
+		t.Helper()
+
+		assert.NoError(t, rdb.Del(ctx, key).Err(), "Failed to clean up Redis keys")
+
+		for _, task := range tasks {
+			taskJSON, _ := transcoder.Encode(task)
+			assert.NoError(t, rdb.ZAdd(ctx, key, redis.Z{Score: float64(task.ID), Member: taskJSON}).Err(), "Failed to add task")
+		}
+	}
+
+	tasks := []TestTask{{ID: 5, Data: "medium"}, {ID: 1, Data: "low"}, {ID: 9, Data: "high"}}
+
+	// LowestFirst verifies that the default order pops the members with the lowest scores.
+	t.Run("LowestFirst", func(t *testing.T) {
+		testKey := "fetcher.domain.com::priority_lowest"
+		fill(t, testKey, tasks...)
+
+		fetcher, err := NewPriorityFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](2))
+		assert.NoError(t, err, "Failed to create priority fetcher")
+
+		fetched, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "low"}, {ID: 5, Data: "medium"}}, fetched, "Expected the lowest scored tasks")
+	})
+
+	// HighestFirst verifies that tasks are popped by descending score and exposed together with their score.
+	t.Run("HighestFirst", func(t *testing.T) {
+		testKey := "fetcher.domain.com::priority_highest"
+		fill(t, testKey, tasks...)
+		assert.NoError(t, rdb.ZAdd(ctx, testKey, redis.Z{Score: 7, Member: "not json"}).Err(), "Failed to add malformed task")
+
+		fetcher, err := NewPriorityFetcher[TestTask](WithClient[TestTask](rdb), WithOrder[TestTask](HighestFirst))
+		assert.NoError(t, err, "Failed to create priority fetcher")
+
+		scored, fetchErr := fetcher.FetchScored(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []Scored[TestTask]{
+			{Task: TestTask{ID: 9, Data: "high"}, Score: 9, Key: testKey},
+			{Task: TestTask{ID: 5, Data: "medium"}, Score: 5, Key: testKey},
+			{Task: TestTask{ID: 1, Data: "low"}, Score: 1, Key: testKey},
+		}, scored, "Expected decodable tasks by descending score")
+		assert.Equal(t, int64(0), rdb.ZCard(ctx, testKey).Val(), "Expected the sorted set to be drained")
+	})
+}
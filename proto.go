@@ -0,0 +1,87 @@
+package fetcher
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtoFormat type selects the wire format used by the ProtoCodec.
+type ProtoFormat int
+
+const (
+	// ProtoBinary serializes messages using the compact protobuf binary wire format. This is the default format.
+	ProtoBinary ProtoFormat = iota
+	// ProtoJSON serializes messages using the canonical protobuf JSON mapping.
+	ProtoJSON
+)
+
+// ProtoCodec is a transcoder for task types implementing proto.Message, such as generated message pointers.
+// It lets fetchers consume tasks written by protobuf producers without hand-written adapters.
+// Binary payloads are carried in Go strings byte for byte, so they survive the string conversion
+// performed on script results; redis itself stores bulk strings without interpreting them.
+// Unknown JSON fields are discarded, so consumers keep working while producers roll out newer schemas.
+type ProtoCodec[T proto.Message] struct {
+	format ProtoFormat
+}
+
+// NewProtoCodec function returns a ProtoCodec for messages of type T using the given wire format.
+// T must be a concrete message type, as messages of an interface type cannot be allocated when decoding.
+// The codec is stateless, so a single instance can be shared freely between fetchers, publishers and goroutines.
+func NewProtoCodec[T proto.Message](format ProtoFormat) *ProtoCodec[T] {
+	return &ProtoCodec[T]{format: format}
+}
+
+// Encode method serializes the message using the configured wire format and stores the result in a string.
+func (c ProtoCodec[T]) Encode(src T) (string, error) {
+	bytes, err := c.EncodeBytes(src)
+
+	return string(bytes), err
+}
+
+// Decode method reconstructs a message of type T from its serialized form stored in a string.
+// Any error encountered during decoding is returned together with the zero value of T.
+func (c ProtoCodec[T]) Decode(src string) (T, error) {
+	return c.DecodeBytes([]byte(src))
+}
+
+// EncodeBytes method serializes the message using the configured wire format.
+func (c ProtoCodec[T]) EncodeBytes(src T) ([]byte, error) {
+	if c.format == ProtoJSON {
+		return protojson.Marshal(src)
+	}
+
+	return proto.Marshal(src)
+}
+
+// DecodeBytes method reconstructs a message of type T from its serialized form.
+// A new message is allocated for every call through the protobuf reflection of T,
+// which works for the nil pointers that generated message types use as their zero value.
+// Interface types such as proto.Message carry no concrete message to allocate, so they yield ErrInvalidMessageType.
+func (c ProtoCodec[T]) DecodeBytes(src []byte) (T, error) {
+	var zero T
+
+	if any(zero) == nil {
+		return zero, fmt.Errorf("%w: %v", ErrInvalidMessageType, reflect.TypeFor[T]())
+	}
+
+	entry, ok := zero.ProtoReflect().New().Interface().(T)
+	if !ok {
+		return zero, proto.Error
+	}
+
+	var err error
+	if c.format == ProtoJSON {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(src, entry)
+	} else {
+		err = proto.Unmarshal(src, entry)
+	}
+
+	if err != nil {
+		return zero, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestProtoCodec is the table-driven test for the round trip of ProtoCodec[T] in both wire formats.
+// Well-known wrapper types stand in for generated messages, as they are regular proto.Message implementations.
+func TestProtoCodec(t *testing.T) {
+	cases := []struct {
+		name   string
+		format ProtoFormat
+		input  *wrapperspb.BytesValue
+	}{
+		{name: "Binary", format: ProtoBinary, input: wrapperspb.Bytes([]byte("task"))},
+		{name: "Binary with invalid UTF-8", format: ProtoBinary, input: wrapperspb.Bytes([]byte{0x00, 0xff, 0xfe, 0x80})},
+		{name: "Binary empty message", format: ProtoBinary, input: wrapperspb.Bytes(nil)},
+		{name: "JSON", format: ProtoJSON, input: wrapperspb.Bytes([]byte{0x00, 0xff})},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := NewProtoCodec[*wrapperspb.BytesValue](tt.format)
+
+			encoded, err := codec.Encode(tt.input)
+			assert.NoError(t, err, "Encode must succeed for valid messages")
+
+			decoded, err := codec.Decode(encoded)
+			assert.NoError(t, err, "Decode must succeed for its own output")
+			assert.True(t, proto.Equal(tt.input, decoded), "Expected the round trip to preserve the message")
+		})
+	}
+
+	// Malformed verifies that invalid payloads are reported instead of producing partial messages.
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := NewProtoCodec[*wrapperspb.BytesValue](ProtoBinary).Decode("\x0a\x05ab")
+		assert.Error(t, err, "Expected truncated binary payload to be rejected")
+
+		_, err = NewProtoCodec[*wrapperspb.BytesValue](ProtoJSON).Decode(`{"value":`)
+		assert.Error(t, err, "Expected malformed JSON payload to be rejected")
+	})
+
+	// InterfaceType verifies that an interface message type is reported instead of panicking while decoding.
+	t.Run("InterfaceType", func(t *testing.T) {
+		codec := NewProtoCodec[proto.Message](ProtoBinary)
+
+		encoded, err := codec.Encode(wrapperspb.Bytes([]byte("task")))
+		assert.NoError(t, err, "Encode must succeed for a concrete message behind the interface")
+
+		_, err = codec.Decode(encoded)
+		assert.ErrorIs(t, err, ErrInvalidMessageType, "Expected ErrInvalidMessageType for an interface message type")
+	})
+}
+
+// TestProtoCodecFetch verifies that binary protobuf payloads survive the round trip through redis
+// and the extraction script unchanged when used with NewRedisFetcher.
+func TestProtoCodecFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	testKey := "fetcher.domain.com::proto_fetch"
+	assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+	codec := NewProtoCodec[*wrapperspb.BytesValue](ProtoBinary)
+	message := wrapperspb.Bytes([]byte{0x00, 0xff, 0x0d, 0x0a, 0x80})
+
+	publisher, err := NewRedisPublisher[*wrapperspb.BytesValue](WithClient[*wrapperspb.BytesValue](rdb), WithTranscoder[*wrapperspb.BytesValue](codec))
+	assert.NoError(t, err, "Failed to create redis publisher")
+
+	_, err = publisher.Publish(ctx, testKey, message)
+	assert.NoError(t, err, "Failed to publish message")
+
+	fetcher, err := NewRedisFetcher[*wrapperspb.BytesValue](WithClient[*wrapperspb.BytesValue](rdb), WithTranscoder[*wrapperspb.BytesValue](codec))
+	assert.NoError(t, err, "Failed to create redis fetcher")
+
+	tasks, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+	assert.NoError(t, fetchErr, "Failed to fetch tasks")
+	assert.Len(t, tasks, 1, "Expected the published message to be fetched")
+	assert.True(t, proto.Equal(message, tasks[0]), "Expected the binary payload to survive the round trip")
+}
@@ -0,0 +1,73 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPublisher struct provides the producing counterpart of RedisFetcher for tasks of type T.
+// It encodes tasks with the configured transcoder and appends them to the tail of a redis list,
+// so tasks published together are fetched in the same order they were published.
+// Sharing the transcoder between both sides guarantees that producers and consumers agree on the format.
+type RedisPublisher[T any] struct {
+	config[T]
+
+	// encoder holds the configured transcoder viewed through its Encoder interface.
+	encoder Encoder[T]
+}
+
+// NewRedisPublisher function constructs a fully configured RedisPublisher instance.
+// It accepts the same functional options as NewRedisFetcher, with WithTaskSize bounding the number of tasks
+// sent by a single RPUSH command. Options that only apply to fetching are ignored.
+// The function returns an error when the redis client is missing or the transcoder cannot encode values.
+func NewRedisPublisher[T any](opts ...options[T]) (*RedisPublisher[T], error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, ok := cfg.transcoder.(Encoder[T])
+	if !ok {
+		return nil, ErrMissingEncoder
+	}
+
+	return &RedisPublisher[T]{config: cfg, encoder: encoder}, nil
+}
+
+// Publish method encodes the tasks and appends them to the tail of the list identified by key.
+// All tasks are encoded before anything is sent, so an encoding error never leaves a partially published batch.
+// Tasks are split into RPUSH commands of at most the configured task size, which are sent in a single pipeline.
+// The method returns the length of the list after the last push and an error if any occurred during the operation.
+func (p *RedisPublisher[T]) Publish(ctx context.Context, key string, tasks ...T) (int64, error) {
+	if len(tasks) == 0 {
+		return p.rdb.LLen(ctx, key).Result()
+	}
+
+	values := make([]interface{}, 0, len(tasks))
+
+	for _, task := range tasks {
+		value, err := p.encoder.Encode(task)
+		if err != nil {
+			return 0, err
+		}
+
+		values = append(values, value)
+	}
+
+	cmds := make([]*redis.IntCmd, 0, (len(values)+p.size-1)/p.size)
+
+	_, err := p.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for start := 0; start < len(values); start += p.size {
+			end := min(start+p.size, len(values))
+			cmds = append(cmds, pipe.RPush(ctx, key, values[start:end]...))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return cmds[len(cmds)-1].Val(), nil
+}
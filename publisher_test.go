@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeOnlyTranscoder is a transcoder that cannot encode values, used to verify publisher validation.
+type decodeOnlyTranscoder struct{}
+
+// Decode method always fails, as the transcoder is never expected to decode anything in the tests.
+func (decodeOnlyTranscoder) Decode(string) (TestTask, error) {
+	return TestTask{}, errors.New("not implemented")
+}
+
+func TestRedisPublisher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	// MissingEncoder verifies that a publisher cannot be built around a transcoder that only decodes.
+	t.Run("MissingEncoder", func(t *testing.T) {
+		_, err := NewRedisPublisher[TestTask](WithClient[TestTask](rdb), WithTranscoder[TestTask](decodeOnlyTranscoder{}))
+		assert.ErrorIs(t, err, ErrMissingEncoder, "Expected ErrMissingEncoder for a decode-only transcoder")
+	})
+
+	// RoundTrip verifies that published tasks are split into batches, keep their order
+	// and are fetched back unchanged by a fetcher sharing the same transcoder.
+	t.Run("RoundTrip", func(t *testing.T) {
+		testKey := "fetcher.domain.com::publisher_round_trip"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		publisher, err := NewRedisPublisher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](2))
+		assert.NoError(t, err, "Failed to create redis publisher")
+
+		tasks := []TestTask{{ID: 1, Data: "task1"}, {ID: 2, Data: "task2"}, {ID: 3, Data: "task3"}}
+
+		length, pubErr := publisher.Publish(ctx, testKey, tasks...)
+		assert.NoError(t, pubErr, "Failed to publish tasks")
+		assert.Equal(t, int64(3), length, "Expected the new queue length to be returned")
+
+		length, pubErr = publisher.Publish(ctx, testKey)
+		assert.NoError(t, pubErr, "Failed to publish an empty batch")
+		assert.Equal(t, int64(3), length, "Expected an empty batch to report the current queue length")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		fetched, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, tasks, fetched, "Expected tasks to be fetched in publishing order")
+	})
+}
@@ -2,24 +2,78 @@ package fetcher
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-// The script defaultExtractCommand is a Lua script that interacts with Redis to fetch tasks from a Redis list.
-// It uses the LPOP command to pop tasks from the list until a specified maximum number of tasks max_tasks are fetched,
-// or the list is empty, whichever comes first. The Lua script ensures efficient retrieval of tasks while respecting the max limit.
+// The script defaultExtractCommand is a Lua script that interacts with Redis to fetch tasks from Redis lists.
+// It uses the LPOP command to pop tasks from every list in KEYS until a specified maximum number of tasks max_tasks
+// are fetched, or all lists are empty, whichever comes first. ARGV[2] selects the strategy used across lists:
+// the sequential strategy drains the lists in the order of KEYS, treating earlier keys as higher priority lanes,
+// while the round-robin strategy pops one task from every non-empty list in turn for fairness.
+// A positive ARGV[3] caps the total size of the popped tasks in bytes. Every task is inspected with LINDEX before
+// it is popped, and extraction stops at the first task that would exceed the budget, unless ARGV[4] allows
+// the first task of the batch to exceed it on its own.
+// The script replies with {key, task} pairs so the caller knows which list every task was taken from.
 var defaultExtractCommand = redis.NewScript(`
-local key = KEYS[1]
 local max_tasks = tonumber(ARGV[1])
+local round_robin = tonumber(ARGV[2]) == 1
+local budget = tonumber(ARGV[3]) or 0
+local allow_first = tonumber(ARGV[4]) == 1
+local bytes = 0
+local exhausted = false
 local tasks = {}
 
-for i = 1, max_tasks do
+local function pop(key)
+	if budget > 0 then
+		local head = redis.call('LINDEX', key, 0)
+		if not head then
+			return false
+		end
+		if bytes + #head > budget and not (allow_first and #tasks == 0) then
+			exhausted = true
+			return false
+		end
+	end
+
 	local task = redis.call('LPOP', key)
-	if not task then
-		break
+	if task then
+		bytes = bytes + #task
+	end
+
+	return task
+end
+
+if round_robin then
+	local active = KEYS
+	while #tasks < max_tasks and #active > 0 and not exhausted do
+		local remaining = {}
+		for _, key in ipairs(active) do
+			if #tasks >= max_tasks or exhausted then
+				break
+			end
+			local task = pop(key)
+			if task then
+				table.insert(tasks, {key, task})
+				table.insert(remaining, key)
+			end
+		end
+		active = remaining
+	end
+else
+	for _, key in ipairs(KEYS) do
+		while #tasks < max_tasks and not exhausted do
+			local task = pop(key)
+			if not task then
+				break
+			end
+			table.insert(tasks, {key, task})
+		end
 	end
-	table.insert(tasks, task)
 end
 
 return tasks
@@ -32,88 +86,308 @@ const defaultTaskSize = 1000
 // RedisFetcher struct provides a redis-backed mechanism for extracting tasks of type T.
 // It encapsulates the redis client, a Lua script used for extraction, a transcoder for decoding,
 // and a configurable batch size that controls how many tasks are retrieved per operation.
-// All fields are configured during construction and are not modified afterward.
+// The configuration is fixed during construction, while the pop mode is detected lazily on the first fetch
+// and stored atomically, so a fetcher is safe for concurrent use.
 type RedisFetcher[T any] struct {
-	transcoder     Transcoder[T]
-	rdb            redis.UniversalClient
-	extractCommand *redis.Script
-	size           int
+	config[T]
+	// native holds the popMode detected on the first fetch, used in place of the default script when supported.
+	native atomic.Int32
 }
 
 // NewRedisFetcher function constructs a fully configured RedisFetcher instance.
 // It applies all provided functional options, validates required dependencies,
 // and initializes default values for any optional configuration not explicitly set.
+// On redis 6.2 and newer the default extraction pops tasks with LPOP and a count, or with LMPOP on redis 7,
+// instead of running the Lua script. The server version is not queried here but once, on the first fetch,
+// so constructing a fetcher never contacts redis. The script is used whenever the version cannot be determined,
+// as well as with a custom script, a byte budget or a dead-letter list. On a cluster client the sequential strategy
+// extracts the slots of the keys one after another to honor their order, see WithStrategy.
+// With a dead-letter list, tasks abandoned by a crash mid-fetch stay in the processing list of the consumer
+// until Reap or StartReaper returns them, as the fetcher does not start the reaper on its own.
 // The function returns an error only when mandatory configuration is missing.
 func NewRedisFetcher[T any](opts ...options[T]) (*RedisFetcher[T], error) {
-	fetcher := &RedisFetcher[T]{}
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Staging tasks for the dead-letter list relies on the built-in extraction script,
+	// so it cannot honor a custom script at the same time.
+	if cfg.deadLetter != "" && cfg.extractCommand != nil {
+		return nil, fmt.Errorf("%w: dead-letter list cannot be combined with a custom script", ErrIncompatibleOptions)
+	}
 
-	for _, opt := range opts {
-		opt(fetcher)
+	if cfg.blockTimeout > 0 && cfg.blockTimeout < time.Second {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBlockTimeout, cfg.blockTimeout)
 	}
 
-	if fetcher.rdb == nil {
-		return nil, ErrEmptyRedisClient
+	// Blocking pops bypass both the extraction script and the processing lists,
+	// so they can only be combined with the default direct extraction.
+	if cfg.blockTimeout > 0 && (cfg.deadLetter != "" || cfg.extractCommand != nil) {
+		return nil, fmt.Errorf("%w: blocking fetch cannot be combined with a custom script or a dead-letter list", ErrIncompatibleOptions)
+	}
+
+	fetcher := &RedisFetcher[T]{config: cfg}
+
+	// Native commands can neither inspect payload sizes nor stage tasks, so only the plain default extraction uses them.
+	if cfg.extractCommand != nil || cfg.byteBudget > 0 || cfg.deadLetter != "" {
+		fetcher.native.Store(int32(popScript))
 	}
 
 	if fetcher.extractCommand == nil {
 		fetcher.extractCommand = defaultExtractCommand
 	}
 
-	if fetcher.size <= 0 {
-		fetcher.size = defaultTaskSize
+	return fetcher, nil
+}
+
+// entry struct describes a single raw task extracted from redis together with the key it was taken from.
+// Entries are produced by parseEntries and consumed by the fetchers before decoding takes place.
+type entry struct {
+	key  string
+	raw  string
+	meta string
+	// err holds the reason the reply element could not be read as a task, which is reported instead of decoding it.
+	err error
+}
+
+// parseEntries function converts the reply of an extraction script into a list of entries.
+// Scripts may reply with plain strings, in which case the first key is assumed to be the source,
+// or with {key, task} pairs that explicitly identify the list every task was taken from.
+// An optional third element carries script specific metadata, such as a lease identifier or a score.
+// Integer tasks are converted to their decimal form. Elements of any other shape are kept as entries
+// carrying ErrUnexpectedReply, so they are reported as decode failures rather than silently discarded.
+func parseEntries(result interface{}, keys []string) []entry {
+	results, ok := result.([]interface{})
+	if !ok || len(results) == 0 {
+		return nil
 	}
 
-	if fetcher.transcoder == nil {
-		fetcher.transcoder = &defaultTranscoder[T]{}
+	var source string
+	if len(keys) > 0 {
+		source = keys[0]
 	}
 
-	return fetcher, nil
+	entries := make([]entry, 0, len(results))
+	for _, item := range results {
+		value, isTuple := item.([]interface{})
+		if !isTuple {
+			entries = append(entries, replyEntry(source, item))
+			continue
+		}
+
+		if len(value) < 2 {
+			entries = append(entries, entry{key: source, raw: fmt.Sprint(value), err: fmt.Errorf("%w: tuple of %d elements", ErrUnexpectedReply, len(value))})
+			continue
+		}
+
+		key, keyOK := value[0].(string)
+		if !keyOK {
+			key = source
+		}
+
+		e := replyEntry(key, value[1])
+		if len(value) > 2 {
+			e.meta, _ = value[2].(string)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// replyEntry function converts a single task element of a script reply into an entry of the key.
+// Strings are used as they are and integers are formatted in decimal, while anything else is flagged as unexpected.
+func replyEntry(key string, value interface{}) entry {
+	switch raw := value.(type) {
+	case string:
+		return entry{key: key, raw: raw}
+	case int64:
+		return entry{key: key, raw: strconv.FormatInt(raw, 10)}
+	default:
+		return entry{key: key, raw: fmt.Sprint(raw), err: fmt.Errorf("%w: %T", ErrUnexpectedReply, raw)}
+	}
 }
 
 // Fetch is a method on the RedisFetcher struct that retrieves a list of tasks from Redis based on the provided keys.
-// It executes a Lua script using the Redis client to fetch up to a maximum number of tasks from the Redis list.
+// It fetches up to a maximum number of tasks from the Redis lists, popping them with LPOP and a count or LMPOP
+// when the server supports them, and with the extraction script otherwise.
+// Tasks that cannot be decoded are skipped; use FetchResult to inspect them.
+// In blocking mode the method waits for tasks to arrive when all lists are empty.
 // The method returns a slice of tasks of type T and an error if any occurred during the operation.
 func (f *RedisFetcher[T]) Fetch(ctx context.Context, keys []string) ([]T, error) {
-	// Run the Redis Lua script using the provided context, Redis client universal client,
-	// and the specified keys, along with the maxTask limit as an argument.
-	result, err := f.extractCommand.Run(ctx, f.rdb, keys, f.size).Result()
+	result, err := f.FetchResult(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Tasks, nil
+}
+
+// FetchResult method retrieves tasks from Redis exactly like Fetch, but also reports the tasks that failed decoding.
+// Every failure carries the raw payload, its source key, its position in the batch and the transcoder error.
+// When a dead-letter list is configured, the reported failures have already been moved there.
+// The method returns the result of the operation and an error if the extraction itself failed.
+func (f *RedisFetcher[T]) FetchResult(ctx context.Context, keys []string) (*Result[T], error) {
+	_, result, err := f.fetch(ctx, keys)
+
+	return result, err
+}
+
+// FetchEnvelopes method retrieves tasks from Redis exactly like Fetch, but returns every decoded task
+// in an envelope together with the raw payload and source key it came from. Callers that cannot handle
+// a task, for instance while shutting down, can pass its envelope to Requeue to return it unchanged.
+// Tasks that cannot be decoded are skipped, and dead-lettered when a dead-letter list is configured.
+// The method returns the envelopes of the decoded tasks and an error if the extraction itself failed.
+func (f *RedisFetcher[T]) FetchEnvelopes(ctx context.Context, keys []string) ([]Envelope[T], error) {
+	entries, result, err := f.fetch(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make(map[int]bool, len(result.Failures))
+	for _, failure := range result.Failures {
+		failed[failure.Index] = true
+	}
+
+	envelopes := make([]Envelope[T], 0, len(result.Tasks))
+	tasks := result.Tasks
+
+	for _, envelope := range f.envelopes(entries) {
+		if failed[envelope.Index] {
+			continue
+		}
+
+		envelope.Task, tasks = tasks[0], tasks[1:]
+		envelopes = append(envelopes, envelope)
+	}
+
+	return envelopes, nil
+}
+
+// fetch method extracts and decodes a batch, returning the extracted entries together with the decoded result.
+func (f *RedisFetcher[T]) fetch(ctx context.Context, keys []string) ([]entry, *Result[T], error) {
+	// With a dead-letter list configured, tasks are staged in the processing list of the consumer
+	// until their outcome is known, so no payload is lost if the process dies mid-batch.
+	if f.deadLetter != "" {
+		return f.fetchStaged(ctx, keys)
+	}
+
+	entries, err := f.take(ctx, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode every raw task extracted from redis.
+	// Elements that are not in the expected format are reported as failures by decode.
+	return entries, f.decode(entries), nil
+}
+
+// FetchRaw method retrieves tasks from Redis exactly like Fetch, but returns them without decoding.
+// Every envelope carries the raw payload, its source key, its position in the batch and the fetch timestamp,
+// and can decode the payload on demand, which suits forwarding, auditing and lazy decoding.
+// Tasks are never dead-lettered, as undecodable ones cannot be told apart, so they are popped directly
+// even when a dead-letter list is configured.
+// The method returns the envelopes in the order the tasks were extracted and an error if the operation failed.
+func (f *RedisFetcher[T]) FetchRaw(ctx context.Context, keys []string) ([]Envelope[T], error) {
+	entries, err := f.take(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.envelopes(entries), nil
+}
+
+// take method removes up to the configured number of tasks from the lists identified by keys.
+// In blocking mode an empty batch is not returned right away; the fetcher waits for the next task instead.
+func (f *RedisFetcher[T]) take(ctx context.Context, keys []string) ([]entry, error) {
+	limit := f.limit(ctx, keys)
+
+	entries, err := f.pop(ctx, keys, limit, nil)
 	// Check if an error occurred during the script execution.
 	if err != nil {
 		return nil, err
 	}
 
-	// Create an empty slice tasks of type T using the make function.
-	// T is a generic type, so the actual type of tasks will be determined at runtime.
-	// The make function initializes the slice with an initial length of 0, meaning it starts empty.
-	// The capacity of the slice will be dynamic, growing as elements are appended to it.
-	// This slice will store the tasks fetched and unmarshalled from Redis.
-	tasks := make([]T, 0)
-	// Check if the result from Redis is a slice of empty interfaces and contains elements.
-	// This ensures that the result is in the expected format of a list and is not empty.
-	if results, ok := result.([]interface{}); ok && len(results) > 0 {
-		// Iterate over each task in the results slice.
-		for _, task := range results {
-			// Check if the task is of type string.
-			// The ok variable indicates whether the type assertion was successful.
-			// If successful, the task is stored in the value variable for further processing.
-			if value, ok := task.(string); ok {
-				// Attempt to unmarshal the task (which is in string format) into the out variable of type T.
-				// The task is expected to be in JSON format as a string, so json.Unmarshal is used to decode it.
-				res, decodeErr := f.transcoder.Decode(value)
-				if decodeErr != nil {
-					// If unmarshalling fails, log the error and continue to the next task.
-					// This ensures that one failed task does not interrupt the processing of other tasks.
-					continue
-				}
-
-				// If unmarshalling is successful, append the unmarshalled task to the tasks slice.
-				// The task is now an instance of type T, and can be used further in the application.
-				tasks = append(tasks, res)
-			}
+	if len(entries) == 0 && f.blockTimeout > 0 {
+		return f.wait(ctx, keys, limit)
+	}
+
+	return entries, nil
+}
+
+// pop method removes up to limit tasks from the lists identified by keys.
+// Native list commands are used instead of the extraction script when the server supports them.
+// The payloads of the prior entries already extracted for the same batch count toward the byte budget.
+func (f *RedisFetcher[T]) pop(ctx context.Context, keys []string, limit int, prior []entry) ([]entry, error) {
+	if mode := f.mode(ctx); mode != popScript {
+		return f.popNative(ctx, keys, limit, mode)
+	}
+
+	// Run the Redis Lua script using the provided context, Redis client universal client,
+	// and the specified keys, along with the maxTask limit and the strategy as arguments.
+	// In a cluster the keys are split by hash slot, running the script once per slot.
+	return f.extractAfter(ctx, keys, limit, prior, extraction{
+		script: f.extractCommand,
+		keys:   func(group []string) []string { return group },
+		args: func(limit int) ([]interface{}, error) {
+			return []interface{}{limit, int(f.strategy)}, nil
+		},
+		budgeted: true,
+	})
+}
+
+// fetchStaged method retrieves tasks through the processing list of the consumer instead of popping them directly.
+// Successfully decoded tasks are released and undecodable ones are moved to the dead-letter list in a single step.
+// Tasks stay leased in the processing list until then, so the reaper can recover them after a crash.
+func (f *RedisFetcher[T]) fetchStaged(ctx context.Context, keys []string) ([]entry, *Result[T], error) {
+	entries, err := f.lease(ctx, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := f.decode(entries)
+
+	// Every entry is released by default, failures are given a dead-letter record below.
+	settlements := make([]settlement, len(entries))
+	for i, e := range entries {
+		settlements[i] = settlement{entry: e}
+	}
+
+	for _, failure := range result.Failures {
+		record, recordErr := newDeadLetterRecord(entries[failure.Index], failure.Err)
+		if recordErr != nil {
+			return nil, nil, recordErr
 		}
+
+		settlements[failure.Index].record = record
 	}
 
-	// After all tasks are processed, the tasks slice will contain all successfully unmarshalled tasks.
-	// If no valid tasks were found or unmarshalled, the tasks slice will be empty, which is valid.
-	return tasks, nil
+	// The staged tasks remain leased when settling fails, so returning the error does not lose any data.
+	if err = f.settle(ctx, settlements); err != nil {
+		return nil, nil, err
+	}
+
+	return entries, result, nil
+}
+
+// Observe method reports that tasks fetched by this fetcher were handled within elapsed.
+// It drives the batch size when adaptive sizing is configured through WithAdaptiveSize and is a no-op otherwise.
+// The Runner calls it after every batch; callers driving the fetcher themselves should do the same.
+func (f *RedisFetcher[T]) Observe(tasks int, elapsed time.Duration) {
+	f.observe(tasks, elapsed)
+}
+
+// Reap method returns tasks abandoned in the processing lists of the source lists identified by keys.
+// Tasks are only ever staged in processing lists when a dead-letter list is configured.
+// See ReliableFetcher.Reap for details.
+func (f *RedisFetcher[T]) Reap(ctx context.Context, keys []string) (int, error) {
+	return f.reap(ctx, keys)
+}
+
+// StartReaper method starts a background routine that periodically reclaims abandoned tasks.
+// See ReliableFetcher.StartReaper for details.
+func (f *RedisFetcher[T]) StartReaper(ctx context.Context, keys []string) <-chan struct{} {
+	return f.startReaper(ctx, keys)
 }
@@ -64,7 +64,7 @@ func TestFetcher(t *testing.T) {
 
 	// Initialize the default transcoder for the TestTask type.
 	// The transcoder is used to decode task data retrieved from Redis.
-	transcoder := &defaultTranscoder[TestTask]{}
+	transcoder := &JSONCodec[TestTask]{}
 
 	// Create a new fetcher instance for the TestTask type using the provided Redis client.
 	// This ensures that the fetcher is initialized with the necessary dependencies for performing operations.
@@ -184,6 +184,35 @@ func TestFetcher(t *testing.T) {
 		assert.Len(t, fetchedTasks, 0, "Empty task list")
 	})
 
+	// FetchResultReportsFailures verifies that FetchResult returns the decodable tasks together with
+	// a failure for every task that could not be decoded, carrying its raw payload, key and position.
+	t.Run("FetchResultReportsFailures", func(t *testing.T) {
+		// Define a malformed JSON payload that will fail during decoding, placed between two valid tasks.
+		malformed := `{"name": "error_data", "value" 456`
+		first, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		second, _ := transcoder.Encode(TestTask{ID: 2, Data: "task2"})
+
+		testKey := "fetcher.domain.com::test_fetch_result"
+		// Remove leftovers of previous runs so that the test always starts from an empty list.
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+		assert.NoError(t, rdb.RPush(ctx, testKey, first, malformed, second).Err(), "Failed to push tasks into Redis")
+
+		// Ensure that the fetcher can be used wherever the extended interface is expected.
+		var resultFetcher ResultFetcher[TestTask] = fetcher
+
+		result, fetchErr := resultFetcher.FetchResult(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks when decode error occurs")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "task1"}, {ID: 2, Data: "task2"}}, result.Tasks, "Expected decodable tasks in list order")
+		assert.Len(t, result.Failures, 1, "Expected a single decode failure")
+
+		failure := result.Failures[0]
+		assert.Equal(t, malformed, failure.Raw, "Expected the raw payload to be reported")
+		assert.Equal(t, testKey, failure.Key, "Expected the source key to be reported")
+		assert.Equal(t, 1, failure.Index, "Expected the batch position to be reported")
+		assert.Error(t, failure.Err, "Expected the transcoder error to be reported")
+		assert.ErrorIs(t, failure, failure.Err, "Expected the failure to unwrap to the transcoder error")
+	})
+
 	// InitFetcherWithoutRedis verifies the behavior of the NewRedisFetcher constructor
 	// when no valid Redis client is provided. This test ensures that the constructor
 	// correctly returns an error, preventing the creation of a fetcher without a required dependency.
@@ -207,7 +236,7 @@ func TestFetcher(t *testing.T) {
 	t.Run("InitFetcherWithScript", func(t *testing.T) {
 		// Initialize the default transcoder for the TestTask type.
 		// The transcoder is used to decode task data retrieved from Redis.
-		tr := &defaultTranscoder[TestTask]{}
+		tr := &JSONCodec[TestTask]{}
 		// Create a new fetcher instance with a custom Lua script and the provided transcoder.
 		// The fetcher should use the provided script and transcoder instead of defaults.
 		fetch, fetcherErr := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithScript[TestTask](testScript), WithTranscoder[TestTask](tr))
@@ -270,7 +299,7 @@ func TestFetcherWithCloseRedisConnection(t *testing.T) {
 	// Assert that the fetcher's transcoder is the default transcoder instance.
 	// This confirms that when no custom transcoder is provided, the fetcher uses the built-in default transcoder.
 	// Using the default ensures that tasks can be decoded correctly without requiring additional configuration.
-	assert.Equal(t, &defaultTranscoder[TestTask]{}, fetcher.transcoder, "Expected fetcher to use the default transcoder")
+	assert.Equal(t, &JSONCodec[TestTask]{}, fetcher.transcoder, "Expected fetcher to use the default transcoder")
 
 	// FailedFetch verifies the behavior of the `Fetch` method when Redis is unavailable.
 	// This test ensures that when the Redis connection is closed before calling Fetch,
@@ -299,3 +328,88 @@ func TestFetcherWithCloseRedisConnection(t *testing.T) {
 		assert.Error(t, fetchErr, "Expected error when fetching with closed Redis connection, but got nil")
 	})
 }
+
+func TestFetcherMultipleKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// push is a helper that fills every lane with the given number of tasks, identified by lane and position.
+	push := func(t *testing.T, keys []string, count int) {
+		t.Helper()
+
+		assert.NoError(t, rdb.Del(ctx, keys...).Err(), "Failed to clean up Redis keys")
+
+		for lane, key := range keys {
+			for i := 0; i < count; i++ {
+				taskJSON, _ := transcoder.Encode(TestTask{ID: lane*10 + i, Data: key})
+				assert.NoError(t, rdb.RPush(ctx, key, taskJSON).Err(), "Failed to push task into Redis")
+			}
+		}
+	}
+
+	ids := func(tasks []TestTask) []int {
+		result := make([]int, 0, len(tasks))
+		for _, task := range tasks {
+			result = append(result, task.ID)
+		}
+
+		return result
+	}
+
+	// Sequential verifies that keys are drained in priority order and that later keys fill up the batch.
+	t.Run("Sequential", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::lane_seq_high", "fetcher.domain.com::lane_seq_low"}
+		push(t, keys, 3)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](4))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []int{0, 1, 2, 10}, ids(tasks), "Expected the high priority lane to be drained first")
+		assert.Equal(t, int64(2), rdb.LLen(ctx, keys[1]).Val(), "Expected the remaining tasks to stay in the low priority lane")
+	})
+
+	// RoundRobin verifies that every key receives a fair share of the batch.
+	t.Run("RoundRobin", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::lane_rr_a", "fetcher.domain.com::lane_rr_b", "fetcher.domain.com::lane_rr_c"}
+		push(t, keys, 2)
+		assert.NoError(t, rdb.Del(ctx, keys[1]).Err(), "Failed to empty the middle lane")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](3), WithStrategy[TestTask](StrategyRoundRobin))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		result, fetchErr := fetcher.FetchResult(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []int{0, 20, 1}, ids(result.Tasks), "Expected tasks to alternate between non-empty lanes")
+	})
+
+	// ReliableRoundRobin verifies that the reliable fetcher honors the strategy and records the source key of every task.
+	t.Run("ReliableRoundRobin", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::lane_reliable_a", "fetcher.domain.com::lane_reliable_b"}
+		push(t, keys, 2)
+
+		fetcher, err := NewReliableFetcher[TestTask](WithClient[TestTask](rdb), WithStrategy[TestTask](StrategyRoundRobin))
+		assert.NoError(t, err, "Failed to create reliable fetcher")
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, 4, "Fetched task count mismatch")
+
+		for i, delivery := range deliveries {
+			assert.Equal(t, keys[i%2], delivery.Key, "Expected deliveries to alternate between lanes")
+			assert.NoError(t, delivery.Ack(ctx), "Failed to acknowledge delivery")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package fetcher
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The script reapCommand is a Lua script that returns tasks with expired leases to their source list.
+// KEYS[1] names the source list, KEYS[2] its lease set and KEYS[3] its hash of leased tasks, followed by
+// the processing lists holding the leased tasks, all of which share the hash tag of the source list.
+// ARGV[1] holds the current time, followed by pairs of the index in KEYS of the processing list of a lease
+// and the lease identifier, or zero for leases that do not name a consumer. The leased task is looked up in the hash,
+// so payloads never travel to the client. Leases that are no longer expired, for instance because the task
+// was settled in the meantime, are left untouched. Tasks still present in their processing list are moved back
+// to the head of the source list, while leases of tasks that were already settled are simply discarded.
+// The script replies with the number of reclaimed tasks.
+var reapCommand = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local reclaimed = 0
+
+for i = 2, #ARGV, 2 do
+	local index = tonumber(ARGV[i])
+	local lease = ARGV[i + 1]
+	local deadline = redis.call('ZSCORE', KEYS[2], lease)
+
+	if deadline and tonumber(deadline) <= now then
+		local task = redis.call('HGET', KEYS[3], lease)
+		if index > 0 and task then
+			if redis.call('LREM', KEYS[index], 1, task) > 0 then
+				redis.call('LPUSH', KEYS[1], task)
+				reclaimed = reclaimed + 1
+			end
+		end
+		redis.call('ZREM', KEYS[2], lease)
+		redis.call('HDEL', KEYS[3], lease)
+	end
+end
+
+return reclaimed
+`)
+
+// defaultReapInterval defines how often the background reaper looks for expired leases.
+const defaultReapInterval = 30 * time.Second
+
+// Reap method performs a single pass over the lease sets of the source lists identified by keys.
+// Every task whose lease has expired and which is still held in a processing list is pushed back
+// to the head of its source list, making it available to all consumers again.
+// The method returns the number of reclaimed tasks and an error if the operation failed.
+func (f *ReliableFetcher[T]) Reap(ctx context.Context, keys []string) (int, error) {
+	return f.reap(ctx, keys)
+}
+
+// StartReaper method starts a background routine that periodically reclaims tasks with expired leases.
+// The routine runs Reap for the source lists identified by keys at the configured reap interval
+// and stops once ctx is canceled. Failures are reported through the configured logger.
+// The returned channel is closed after the routine has stopped.
+func (f *ReliableFetcher[T]) StartReaper(ctx context.Context, keys []string) <-chan struct{} {
+	return f.startReaper(ctx, keys)
+}
+
+// reap method implements a single reaper pass shared by all fetchers that lease tasks.
+func (c *config[T]) reap(ctx context.Context, keys []string) (int, error) {
+	now := c.clock().UnixMilli()
+	reclaimed := 0
+
+	for _, key := range keys {
+		for {
+			// Expired leases are processed in chunks bounded by the task size,
+			// so a single script invocation never blocks redis for too long.
+			expired, err := c.rdb.ZRangeByScore(ctx, leasesKey(key), &redis.ZRangeBy{
+				Min:   "-inf",
+				Max:   strconv.FormatInt(now, 10),
+				Count: int64(c.size),
+			}).Result()
+			if err != nil {
+				return reclaimed, err
+			}
+
+			if len(expired) == 0 {
+				break
+			}
+
+			scriptKeys, args := reapArgs(key, now, expired)
+
+			count, err := reapCommand.Run(ctx, c.rdb, scriptKeys, args...).Int()
+			if err != nil {
+				return reclaimed, err
+			}
+
+			reclaimed += count
+
+			if len(expired) < c.size {
+				break
+			}
+		}
+	}
+
+	return reclaimed, nil
+}
+
+// reapArgs function builds the keys and arguments of the reap script for the expired leases of the source key.
+// The processing list of every lease is derived from the consumer named by its identifier and declared once
+// in the keys, as the script may only touch declared keys. Leases that cannot be parsed are passed without
+// a processing list, so they are discarded instead of blocking the reaper for good.
+func reapArgs(key string, now int64, expired []string) ([]string, []interface{}) {
+	scriptKeys := []string{key, leasesKey(key), leasedKey(key)}
+	args := make([]interface{}, 0, len(expired)*2+1)
+	args = append(args, now)
+
+	index := make(map[string]int)
+
+	for _, lease := range expired {
+		consumer, ok := leaseConsumer(lease)
+		if !ok {
+			args = append(args, 0, lease)
+			continue
+		}
+
+		processing := processingKey(key, consumer)
+		if _, ok = index[processing]; !ok {
+			scriptKeys = append(scriptKeys, processing)
+			index[processing] = len(scriptKeys)
+		}
+
+		args = append(args, index[processing], lease)
+	}
+
+	return scriptKeys, args
+}
+
+// startReaper method implements the background reaper routine shared by all fetchers that lease tasks.
+func (c *config[T]) startReaper(ctx context.Context, keys []string) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(c.reapInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reclaimed, err := c.reap(ctx, keys)
+				if err != nil && ctx.Err() == nil {
+					c.logger.Error().Err(err).Strs("keys", keys).Msg("failed to reclaim expired tasks")
+				}
+
+				if reclaimed > 0 {
+					c.logger.Info().Int("reclaimed", reclaimed).Strs("keys", keys).Msg("reclaimed expired tasks")
+				}
+			}
+		}
+	}()
+
+	return done
+}
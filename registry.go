@@ -0,0 +1,143 @@
+package fetcher
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+// RegistryCodec is a transcoder that decodes several concrete task kinds sharing one list into an interface type T.
+// Every payload is a JSON object carrying a "type" field next to the fields of the concrete value, and every type
+// name is registered with RegisterType to map it to a concrete Go type. Decode returns the concrete value as T,
+// so callers can switch on it, while Encode adds the registered name of the dynamic type of the value.
+// Concrete types must not use the "type" field for their own data.
+type RegistryCodec[T any] struct {
+	mu      sync.RWMutex
+	decoder map[string]func(src []byte) (T, error)
+	names   map[reflect.Type]string
+}
+
+// discriminator struct reads the type field of a payload without decoding the rest of it.
+type discriminator struct {
+	Type string `json:"type"`
+}
+
+// NewRegistryCodec function returns an empty RegistryCodec for the interface type T.
+// Concrete types have to be registered with RegisterType before payloads of their kind can be encoded or decoded.
+func NewRegistryCodec[T any]() *RegistryCodec[T] {
+	return &RegistryCodec[T]{decoder: map[string]func(src []byte) (T, error){}, names: map[reflect.Type]string{}}
+}
+
+// RegisterType function maps the type name to the concrete type C in the registry.
+// C may be a struct or a pointer to a struct and must be assignable to T; pointer types decode into a new value.
+// The function returns ErrInvalidType when C is not assignable to T and ErrTypeRegistered when the name
+// or the type is already registered.
+// It is safe to register types while the codec is in use.
+func RegisterType[T, C any](r *RegistryCodec[T], name string) error {
+	concrete := reflect.TypeFor[C]()
+
+	if !concrete.AssignableTo(reflect.TypeFor[T]()) {
+		return fmt.Errorf("%w: %s is not assignable to %s", ErrInvalidType, concrete, reflect.TypeFor[T]())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.decoder[name]; ok {
+		return fmt.Errorf("%w: name %q", ErrTypeRegistered, name)
+	}
+
+	if _, ok := r.names[concrete]; ok {
+		return fmt.Errorf("%w: %s", ErrTypeRegistered, concrete)
+	}
+
+	r.decoder[name] = func(src []byte) (T, error) {
+		var value C
+
+		if err := json.Unmarshal(src, &value); err != nil {
+			var zero T
+			return zero, err
+		}
+
+		return any(value).(T), nil
+	}
+
+	r.names[concrete] = name
+
+	return nil
+}
+
+// Encode method converts the value into a JSON object carrying the registered name of its dynamic type.
+func (r *RegistryCodec[T]) Encode(src T) (string, error) {
+	bytes, err := r.EncodeBytes(src)
+
+	return string(bytes), err
+}
+
+// Decode method decodes the payload into the concrete type registered for its type name and returns it as T.
+func (r *RegistryCodec[T]) Decode(src string) (T, error) {
+	return r.DecodeBytes([]byte(src))
+}
+
+// EncodeBytes method converts the value into a JSON object carrying the registered name of its dynamic type.
+// It returns ErrUnknownType when the dynamic type of the value is not registered,
+// and ErrInvalidType when the value does not encode to a JSON object.
+func (r *RegistryCodec[T]) EncodeBytes(src T) ([]byte, error) {
+	concrete := reflect.TypeOf(src)
+
+	r.mu.RLock()
+	name, ok := r.names[concrete]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownType, concrete)
+	}
+
+	fields, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) < 2 || fields[0] != '{' {
+		return nil, fmt.Errorf("%w: %s does not encode to a JSON object", ErrInvalidType, concrete)
+	}
+
+	header, err := json.Marshal(discriminator{Type: name})
+	if err != nil {
+		return nil, err
+	}
+
+	// The type field is spliced in front of the fields of the value, separated by a comma unless the object is empty.
+	payload := header[:len(header)-1]
+	if len(bytes.TrimSpace(fields[1:len(fields)-1])) > 0 {
+		payload = append(payload, ',')
+	}
+
+	payload = append(payload, fields[1:]...)
+
+	return payload, nil
+}
+
+// DecodeBytes method decodes the payload into the concrete type registered for its type name and returns it as T.
+// It returns ErrUnknownType when the payload carries no type name or a name that is not registered.
+func (r *RegistryCodec[T]) DecodeBytes(src []byte) (T, error) {
+	var zero T
+	var kind discriminator
+
+	if err := json.Unmarshal(src, &kind); err != nil {
+		return zero, err
+	}
+
+	r.mu.RLock()
+	decode, ok := r.decoder[kind.Type]
+	r.mu.RUnlock()
+
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrUnknownType, kind.Type)
+	}
+
+	return decode(src)
+}
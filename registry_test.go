@@ -0,0 +1,144 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// Job is the interface implemented by every task kind pushed into the polymorphic test list.
+type Job interface {
+	Kind() string
+}
+
+// EmailJob is a task kind registered as a value type.
+type EmailJob struct {
+	To string `json:"to"`
+}
+
+// Kind method returns the name of the email task kind.
+func (EmailJob) Kind() string { return "email" }
+
+// ResizeJob is a task kind registered as a pointer type.
+type ResizeJob struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Kind method returns the name of the resize task kind.
+func (*ResizeJob) Kind() string { return "resize" }
+
+// PingJob is a task kind without fields.
+type PingJob struct{}
+
+// Kind method returns the name of the ping task kind.
+func (PingJob) Kind() string { return "ping" }
+
+// CodeJob is a task kind that does not encode to a JSON object.
+type CodeJob int
+
+// Kind method returns the name of the code task kind.
+func (CodeJob) Kind() string { return "code" }
+
+// newJobCodec is a helper that builds a registry codec with every test task kind registered.
+func newJobCodec(t *testing.T) *RegistryCodec[Job] {
+	t.Helper()
+
+	codec := NewRegistryCodec[Job]()
+	assert.NoError(t, RegisterType[Job, EmailJob](codec, "email"), "Failed to register email job")
+	assert.NoError(t, RegisterType[Job, *ResizeJob](codec, "resize"), "Failed to register resize job")
+	assert.NoError(t, RegisterType[Job, PingJob](codec, "ping"), "Failed to register ping job")
+
+	return codec
+}
+
+// TestRegistryCodec is the table-driven test for RegistryCodec[T].
+// It verifies that payloads are decoded into the concrete type registered for their type name
+// and that encoding adds the registered name of the dynamic type.
+func TestRegistryCodec(t *testing.T) {
+	codec := newJobCodec(t)
+
+	cases := []struct {
+		name    string
+		value   Job
+		encoded string
+	}{
+		{name: "Value type", value: EmailJob{To: "a@example.com"}, encoded: `{"type":"email","to":"a@example.com"}`},
+		{name: "Pointer type", value: &ResizeJob{Width: 2, Height: 3}, encoded: `{"type":"resize","width":2,"height":3}`},
+		{name: "Empty struct", value: PingJob{}, encoded: `{"type":"ping"}`},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := codec.Encode(tt.value)
+			assert.NoError(t, err, "Encode must succeed for registered types")
+			assert.JSONEq(t, tt.encoded, encoded, "Unexpected encoding")
+
+			decoded, err := codec.Decode(encoded)
+			assert.NoError(t, err, "Decode must succeed for registered types")
+			assert.Equal(t, tt.value, decoded, "Expected the concrete value to be restored")
+		})
+	}
+
+	// Unknown verifies that unregistered type names and values are rejected.
+	t.Run("Unknown", func(t *testing.T) {
+		_, err := codec.Decode(`{"type":"fax"}`)
+		assert.ErrorIs(t, err, ErrUnknownType, "Expected unknown type names to be rejected")
+
+		_, err = codec.Decode(`{"to":"a@example.com"}`)
+		assert.ErrorIs(t, err, ErrUnknownType, "Expected payloads without a type name to be rejected")
+
+		_, err = codec.Encode(&EmailJob{})
+		assert.ErrorIs(t, err, ErrUnknownType, "Expected unregistered types to be rejected")
+	})
+
+	// Registration verifies that conflicting and incompatible registrations are rejected.
+	t.Run("Registration", func(t *testing.T) {
+		assert.ErrorIs(t, RegisterType[Job, PingJob](codec, "noop"), ErrTypeRegistered, "Expected duplicate types to be rejected")
+		assert.ErrorIs(t, RegisterType[Job, *PingJob](codec, "email"), ErrTypeRegistered, "Expected duplicate names to be rejected")
+		assert.ErrorIs(t, RegisterType[Job, ResizeJob](codec, "resize_value"), ErrInvalidType, "Expected types not implementing T to be rejected")
+
+		scalar := NewRegistryCodec[Job]()
+		assert.NoError(t, RegisterType[Job, CodeJob](scalar, "code"), "Failed to register code job")
+
+		_, err := scalar.Encode(CodeJob(7))
+		assert.ErrorIs(t, err, ErrInvalidType, "Expected values that are not JSON objects to be rejected")
+	})
+}
+
+// TestRegistryCodecFetch verifies that several task kinds pushed into one list are fetched as their concrete types.
+func TestRegistryCodecFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	testKey := "fetcher.domain.com::registry_fetch"
+	assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+	codec := newJobCodec(t)
+	jobs := []Job{EmailJob{To: "a@example.com"}, &ResizeJob{Width: 1, Height: 1}}
+
+	publisher, err := NewRedisPublisher[Job](WithClient[Job](rdb), WithTranscoder[Job](codec))
+	assert.NoError(t, err, "Failed to create redis publisher")
+
+	_, err = publisher.Publish(ctx, testKey, jobs...)
+	assert.NoError(t, err, "Failed to publish jobs")
+
+	fetcher, err := NewRedisFetcher[Job](WithClient[Job](rdb), WithTranscoder[Job](codec))
+	assert.NoError(t, err, "Failed to create redis fetcher")
+
+	fetched, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+	assert.NoError(t, fetchErr, "Failed to fetch tasks")
+	assert.Equal(t, jobs, fetched, "Expected every job to be fetched as its concrete type")
+}
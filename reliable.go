@@ -0,0 +1,319 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The script reliableExtractCommand is a Lua script that moves tasks from source lists into processing lists.
+// KEYS is expected to contain quadruples of a source list, the processing list of the consumer, the lease set
+// and the hash of leased tasks. Every task popped with LPOP is atomically appended to the matching processing list
+// with RPUSH, so a task is never absent from both lists, even if the worker crashes right after the call.
+// A lease scored by its deadline ARGV[2] is recorded for every task under a short identifier built from the batch
+// token ARGV[3], and the task is stored under the same identifier in the hash, so the reaper can find it.
+// ARGV[4] selects the strategy used across source lists and ARGV[5] and ARGV[6] describe the byte budget,
+// exactly like the strategy and budget arguments of defaultExtractCommand.
+// The script replies with {key, task, lease} triples so the caller can settle every task later.
+var reliableExtractCommand = redis.NewScript(`
+local max_tasks = tonumber(ARGV[1])
+local deadline = tonumber(ARGV[2])
+local token = ARGV[3]
+local round_robin = tonumber(ARGV[4]) == 1
+local budget = tonumber(ARGV[5]) or 0
+local allow_first = tonumber(ARGV[6]) == 1
+local bytes = 0
+local exhausted = false
+local tasks = {}
+
+local function move(k)
+	local key = KEYS[k]
+	if budget > 0 then
+		local head = redis.call('LINDEX', key, 0)
+		if head and bytes + #head > budget and not (allow_first and #tasks == 0) then
+			exhausted = true
+			return false
+		end
+	end
+
+	local task = redis.call('LPOP', key)
+	if not task then
+		return false
+	end
+
+	bytes = bytes + #task
+
+	local processing = KEYS[k + 1]
+	redis.call('RPUSH', processing, task)
+
+	local lease = #tasks .. ':' .. token
+	redis.call('ZADD', KEYS[k + 2], deadline, lease)
+	redis.call('HSET', KEYS[k + 3], lease, task)
+	table.insert(tasks, {key, task, lease})
+
+	return true
+end
+
+if round_robin then
+	local active = {}
+	for k = 1, #KEYS, 4 do
+		table.insert(active, k)
+	end
+
+	while #tasks < max_tasks and #active > 0 and not exhausted do
+		local remaining = {}
+		for _, k in ipairs(active) do
+			if #tasks >= max_tasks or exhausted then
+				break
+			end
+			if move(k) then
+				table.insert(remaining, k)
+			end
+		end
+		active = remaining
+	end
+else
+	for k = 1, #KEYS, 4 do
+		while #tasks < max_tasks and not exhausted and move(k) do
+		end
+	end
+end
+
+return tasks
+`)
+
+// The script ackCommand is a Lua script that removes a leased task from its processing list and drops its lease.
+// It replies with the number of removed tasks, which is zero when the task is no longer leased.
+var ackCommand = redis.NewScript(`
+local removed = redis.call('LREM', KEYS[1], 1, ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[2])
+redis.call('HDEL', KEYS[3], ARGV[2])
+
+return removed
+`)
+
+// The script nackCommand is a Lua script that returns a leased task from a processing list to its source list.
+// The task is removed from the processing list and pushed to the head of the source list in a single step,
+// so it becomes the next task handed out. Tasks that are no longer leased are left untouched.
+var nackCommand = redis.NewScript(`
+redis.call('ZREM', KEYS[3], ARGV[2])
+redis.call('HDEL', KEYS[4], ARGV[2])
+
+if redis.call('LREM', KEYS[2], 1, ARGV[1]) == 0 then
+	return 0
+end
+
+redis.call('LPUSH', KEYS[1], ARGV[1])
+
+return 1
+`)
+
+// defaultVisibilityTimeout defines how long a leased task may stay unacknowledged before it can be reclaimed.
+// The value is deliberately generous so that slow handlers are not interrupted by the reaper.
+const defaultVisibilityTimeout = 5 * time.Minute
+
+// ReliableFetcher struct provides an at-least-once alternative to RedisFetcher.
+// Instead of removing tasks from redis, it atomically moves them into a processing list owned by the consumer
+// and hands out deliveries that must be acknowledged or rejected explicitly once the task has been handled.
+// Tasks that were fetched but never acknowledged stay in the processing list, so a crash never loses them.
+type ReliableFetcher[T any] struct {
+	config[T]
+}
+
+// Delivery struct represents a task leased by a ReliableFetcher together with the data needed to settle it.
+// Exactly one of Ack or Nack is expected to be called for every delivery once the task has been handled.
+type Delivery[T any] struct {
+	// Task holds the decoded value of the leased task.
+	Task T
+	// Key holds the name of the source list the task was taken from.
+	Key string
+
+	raw        string
+	lease      string
+	processing string
+	rdb        redis.UniversalClient
+}
+
+// NewReliableFetcher function constructs a fully configured ReliableFetcher instance.
+// It accepts the same functional options as NewRedisFetcher, with WithConsumer selecting the processing lists.
+// Custom extraction scripts configured through WithScript are ignored, as the processing lists require the built-in one.
+// The function returns an error only when mandatory configuration is missing.
+func NewReliableFetcher[T any](opts ...options[T]) (*ReliableFetcher[T], error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReliableFetcher[T]{config: cfg}, nil
+}
+
+// Fetch method leases up to the configured number of tasks from the source lists identified by keys.
+// Source lists are drained according to the configured strategy, and every task is moved into the processing list of the consumer.
+// Each task is leased for the configured visibility timeout, after which the reaper may return it to its source list.
+// Tasks that cannot be decoded are removed from the processing list and skipped, matching RedisFetcher.Fetch,
+// or moved to the dead-letter list when one is configured.
+// The method returns the deliveries of all successfully decoded tasks and an error if the operation failed.
+func (f *ReliableFetcher[T]) Fetch(ctx context.Context, keys []string) ([]*Delivery[T], error) {
+	entries, err := f.lease(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*Delivery[T], 0, len(entries))
+	rejected := make([]settlement, 0)
+
+	for i, e := range entries {
+		delivery := &Delivery[T]{Key: e.key, raw: e.raw, lease: e.meta, processing: processingKey(e.key, f.consumer), rdb: f.rdb}
+
+		task, decodeErr := f.decodeEntry(e)
+		if decodeErr != nil {
+			f.logFailure(DecodeFailure{Raw: e.raw, Key: e.key, Index: i, Err: decodeErr})
+
+			// Undecodable tasks would otherwise stay in the processing list forever,
+			// so they are collected and removed or dead-lettered once all tasks have been inspected.
+			var record string
+			if f.deadLetter != "" {
+				if record, err = newDeadLetterRecord(e, decodeErr); err != nil {
+					return nil, err
+				}
+			}
+
+			rejected = append(rejected, settlement{entry: e, record: record})
+			continue
+		}
+
+		delivery.Task = task
+		deliveries = append(deliveries, delivery)
+	}
+
+	// The leased tasks remain in the processing lists when the cleanup fails,
+	// so returning the error does not lose any data.
+	if err = f.settle(ctx, rejected); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Observe method reports that tasks delivered by this fetcher were handled within elapsed.
+// It drives the batch size when adaptive sizing is configured through WithAdaptiveSize and is a no-op otherwise.
+func (f *ReliableFetcher[T]) Observe(tasks int, elapsed time.Duration) {
+	f.observe(tasks, elapsed)
+}
+
+// lease method moves up to the configured number of tasks from the source lists into the processing lists
+// of the consumer, recording a lease for every task. It is shared by all fetchers that stage tasks in redis.
+// The method returns the leased entries in the order they were extracted.
+func (c *config[T]) lease(ctx context.Context, keys []string) ([]entry, error) {
+	deadline := c.clock().Add(c.visibilityTimeout).UnixMilli()
+
+	return c.extract(ctx, keys, c.limit(ctx, keys), extraction{
+		script: reliableExtractCommand,
+		// Group every source key with the processing list of this consumer, its lease set and its leased tasks,
+		// as expected by the extraction script. All of them share the hash slot of the source key.
+		keys: func(group []string) []string {
+			scriptKeys := make([]string, 0, len(group)*4)
+			for _, key := range group {
+				scriptKeys = append(scriptKeys, key, processingKey(key, c.consumer), leasesKey(key), leasedKey(key))
+			}
+
+			return scriptKeys
+		},
+		// Every invocation receives its own token, so lease identifiers never collide between invocations.
+		// The token ends with the consumer, which lets the reaper find the processing list of every lease.
+		args: func(limit int) ([]interface{}, error) {
+			token, err := newToken()
+			if err != nil {
+				return nil, err
+			}
+
+			token += ":" + c.consumer
+
+			return []interface{}{limit, deadline, token, int(c.strategy)}, nil
+		},
+		budgeted: true,
+	})
+}
+
+// Ack method acknowledges the delivery by removing the task from the processing list of the consumer.
+// It must be called once the task has been handled successfully and will not be retried.
+// The method returns ErrDeliveryNotFound when the task is no longer present in the processing list.
+func (d *Delivery[T]) Ack(ctx context.Context) error {
+	removed, err := ackCommand.Run(ctx, d.rdb, []string{d.processing, leasesKey(d.Key), leasedKey(d.Key)}, d.raw, d.lease).Int()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// Nack method rejects the delivery by returning the task to the head of its source list.
+// It must be called when the task could not be handled and should be retried by any consumer.
+// The method returns ErrDeliveryNotFound when the task is no longer present in the processing list.
+func (d *Delivery[T]) Nack(ctx context.Context) error {
+	moved, err := nackCommand.Run(ctx, d.rdb, []string{d.Key, d.processing, leasesKey(d.Key), leasedKey(d.Key)}, d.raw, d.lease).Int()
+	if err != nil {
+		return err
+	}
+
+	if moved == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// processingKey function builds the name of the processing list owned by consumer for the source key.
+func processingKey(key, consumer string) string {
+	return relatedKey(key, "processing:"+consumer)
+}
+
+// leasesKey function builds the name of the sorted set tracking the lease deadlines of the source key.
+func leasesKey(key string) string {
+	return relatedKey(key, "leases")
+}
+
+// leasedKey function builds the name of the hash holding every leased task of the source key by lease identifier.
+func leasedKey(key string) string {
+	return relatedKey(key, "leased")
+}
+
+// leaseConsumer function extracts the consumer from a lease identifier built by the extraction script,
+// which has the form index:token:consumer. It reports false when the identifier cannot be parsed.
+func leaseConsumer(lease string) (string, bool) {
+	parts := strings.SplitN(lease, ":", 3)
+	if len(parts) < 3 || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[2], true
+}
+
+// relatedKey function builds the name of an auxiliary key that belongs to the source key.
+// The source key is wrapped into a hash tag unless it already carries one, so both keys always
+// hash to the same cluster slot and can be modified together by a single Lua script.
+func relatedKey(key, suffix string) string {
+	if hashTag(key) != "" {
+		return key + ":" + suffix
+	}
+
+	return "{" + key + "}:" + suffix
+}
+
+// newToken function generates a random hexadecimal token used to make lease identifiers unique.
+func newToken() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,251 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReliableFetcher(t *testing.T) {
+	t.Parallel()
+
+	// Create a new background context for the operation.
+	// This context is typically used when no cancellation, timeout, or specific context values are needed.
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	// Ensure that the Redis client is closed when the test function completes.
+	defer rdb.Close()
+
+	// Perform a health check by pinging the Redis server using the provided context.
+	// This ensures that the connection to the Redis server is active and functional.
+	err := rdb.Ping(ctx).Err()
+	assert.NoError(t, err, "Expected Redis server to respond to ping without errors")
+
+	// Initialize the default transcoder for the TestTask type.
+	// The transcoder is used to encode the test tasks pushed into Redis.
+	transcoder := &JSONCodec[TestTask]{}
+
+	// Create a new reliable fetcher with a fixed consumer name so that the processing list is predictable.
+	fetcher, err := NewReliableFetcher[TestTask](WithClient[TestTask](rdb), WithConsumer[TestTask]("worker-1"))
+	assert.NoError(t, err, "Failed to create reliable fetcher")
+	assert.NotNil(t, fetcher, "Expected fetcher instance to be initialized and not nil")
+	assert.Equal(t, "worker-1", fetcher.consumer, "Expected fetcher to use the provided consumer name")
+
+	// LeaseAndAck verifies that fetched tasks are kept in the processing list until they are acknowledged.
+	t.Run("LeaseAndAck", func(t *testing.T) {
+		testKey := "fetcher.domain.com::reliable_ack"
+		// Remove leftovers of previous runs so that the test always starts from an empty list.
+		assert.NoError(t, rdb.Del(ctx, testKey, processingKey(testKey, fetcher.consumer), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+		testTasks := []TestTask{{ID: 1, Data: "task1"}, {ID: 2, Data: "task2"}}
+
+		for _, task := range testTasks {
+			taskJSON, _ := transcoder.Encode(task)
+			assert.NoError(t, rdb.RPush(ctx, testKey, taskJSON).Err(), "Failed to push task into Redis")
+		}
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, len(testTasks), "Fetched task count mismatch")
+		assert.Equal(t, testTasks[0], deliveries[0].Task, "Expected tasks to be fetched in list order")
+		assert.Equal(t, testKey, deliveries[0].Key, "Expected delivery to reference its source key")
+
+		// The leased tasks must have left the source list and be present in the processing list.
+		processing := processingKey(testKey, "worker-1")
+		assert.Equal(t, int64(0), rdb.LLen(ctx, testKey).Val(), "Expected source list to be drained")
+		assert.Equal(t, int64(2), rdb.LLen(ctx, processing).Val(), "Expected tasks to be held in the processing list")
+		assert.Equal(t, int64(2), rdb.HLen(ctx, leasedKey(testKey)).Val(), "Expected leased tasks to be stored by lease identifier")
+
+		// Leases only carry a short identifier, while the payloads stay in the hash of leased tasks.
+		for _, lease := range rdb.ZRange(ctx, leasesKey(testKey), 0, -1).Val() {
+			consumer, ok := leaseConsumer(lease)
+			assert.True(t, ok, "Expected the lease identifier to be parsed")
+			assert.Equal(t, "worker-1", consumer, "Expected the lease to name its consumer")
+			assert.NotContains(t, lease, "task", "Expected the lease not to carry the payload")
+		}
+
+		for _, delivery := range deliveries {
+			assert.NoError(t, delivery.Ack(ctx), "Failed to acknowledge delivery")
+		}
+
+		// Acknowledged tasks are removed for good, and acknowledging twice reports a missing delivery.
+		assert.Equal(t, int64(0), rdb.LLen(ctx, processing).Val(), "Expected processing list to be empty after ack")
+		assert.Equal(t, int64(0), rdb.HLen(ctx, leasedKey(testKey)).Val(), "Expected leased tasks to be released after ack")
+		assert.ErrorIs(t, deliveries[0].Ack(ctx), ErrDeliveryNotFound, "Expected ErrDeliveryNotFound on second ack")
+	})
+
+	// Nack verifies that rejected tasks are returned to the head of their source list.
+	t.Run("Nack", func(t *testing.T) {
+		testKey := "fetcher.domain.com::reliable_nack"
+		// Remove leftovers of previous runs so that the test always starts from an empty list.
+		assert.NoError(t, rdb.Del(ctx, testKey, processingKey(testKey, fetcher.consumer), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+
+		first, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		second, _ := transcoder.Encode(TestTask{ID: 2, Data: "task2"})
+		assert.NoError(t, rdb.RPush(ctx, testKey, first).Err(), "Failed to push task into Redis")
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, 1, "Fetched task count mismatch")
+
+		// Push another task while the first one is leased, then reject the leased one.
+		assert.NoError(t, rdb.RPush(ctx, testKey, second).Err(), "Failed to push task into Redis")
+		assert.NoError(t, deliveries[0].Nack(ctx), "Failed to reject delivery")
+
+		assert.Equal(t, []string{first, second}, rdb.LRange(ctx, testKey, 0, -1).Val(), "Expected rejected task at the head")
+		assert.Equal(t, int64(0), rdb.LLen(ctx, processingKey(testKey, "worker-1")).Val(), "Expected processing list to be empty")
+		assert.ErrorIs(t, deliveries[0].Nack(ctx), ErrDeliveryNotFound, "Expected ErrDeliveryNotFound on second nack")
+	})
+
+	// FailedDecodeValue verifies that undecodable tasks are skipped and do not linger in the processing list.
+	t.Run("FailedDecodeValue", func(t *testing.T) {
+		testKey := "fetcher.domain.com::reliable_failed_decode"
+		// Remove leftovers of previous runs so that the test always starts from an empty list.
+		assert.NoError(t, rdb.Del(ctx, testKey, processingKey(testKey, fetcher.consumer), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+
+		assert.NoError(t, rdb.RPush(ctx, testKey, `{"id": 1, "data" "broken"`).Err(), "Failed to push task into Redis")
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks when decode error occurs")
+		assert.Len(t, deliveries, 0, "Expected undecodable task to be skipped")
+		assert.Equal(t, int64(0), rdb.LLen(ctx, processingKey(testKey, "worker-1")).Val(), "Expected processing list to be empty")
+	})
+
+	// ProcessingKey verifies that processing lists always share the cluster slot of their source list.
+	t.Run("ProcessingKey", func(t *testing.T) {
+		assert.Equal(t, "{queue}:processing:w", processingKey("queue", "w"), "Expected plain keys to be wrapped in a hash tag")
+		assert.Equal(t, "{queue}:a:processing:w", processingKey("{queue}:a", "w"), "Expected existing hash tags to be preserved")
+		assert.Equal(t, "{queue}:leased", leasedKey("queue"), "Expected leased tasks to share the slot of the source list")
+
+		consumer, ok := leaseConsumer("3:0a1b:worker:eu")
+		assert.True(t, ok, "Expected a lease identifier to be parsed")
+		assert.Equal(t, "worker:eu", consumer, "Expected consumers containing colons to be preserved")
+
+		_, ok = leaseConsumer("not a lease")
+		assert.False(t, ok, "Expected a malformed lease identifier to be rejected")
+	})
+}
+
+func TestReliableFetcherReaper(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// Create a fetcher whose leases expire almost immediately, so that the reaper can reclaim them in the test.
+	fetcher, err := NewReliableFetcher[TestTask](
+		WithClient[TestTask](rdb),
+		WithConsumer[TestTask]("crashed-worker"),
+		WithVisibilityTimeout[TestTask](time.Millisecond),
+		WithReapInterval[TestTask](10*time.Millisecond),
+	)
+	assert.NoError(t, err, "Failed to create reliable fetcher")
+	assert.Equal(t, time.Millisecond, fetcher.visibilityTimeout, "Expected fetcher to use the provided visibility timeout")
+
+	// Reap verifies that abandoned tasks are returned to their source list while settled ones are not.
+	t.Run("Reap", func(t *testing.T) {
+		testKey := "fetcher.domain.com::reaper_single_pass"
+		// Remove leftovers of previous runs so that the test always starts from an empty list.
+		assert.NoError(t, rdb.Del(ctx, testKey, processingKey(testKey, fetcher.consumer), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+
+		for _, task := range []TestTask{{ID: 1, Data: "task1"}, {ID: 2, Data: "task2"}} {
+			taskJSON, _ := transcoder.Encode(task)
+			assert.NoError(t, rdb.RPush(ctx, testKey, taskJSON).Err(), "Failed to push task into Redis")
+		}
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, 2, "Fetched task count mismatch")
+
+		// Acknowledge the first task and abandon the second one, simulating a crash mid-batch.
+		assert.NoError(t, deliveries[0].Ack(ctx), "Failed to acknowledge delivery")
+		time.Sleep(5 * time.Millisecond)
+
+		reclaimed, reapErr := fetcher.Reap(ctx, []string{testKey})
+		assert.NoError(t, reapErr, "Failed to reap expired leases")
+		assert.Equal(t, 1, reclaimed, "Expected only the abandoned task to be reclaimed")
+
+		expected, _ := transcoder.Encode(deliveries[1].Task)
+		assert.Equal(t, []string{expected}, rdb.LRange(ctx, testKey, 0, -1).Val(), "Expected abandoned task back in the source list")
+		assert.Equal(t, int64(0), rdb.LLen(ctx, processingKey(testKey, "crashed-worker")).Val(), "Expected processing list to be empty")
+		assert.Equal(t, int64(0), rdb.ZCard(ctx, leasesKey(testKey)).Val(), "Expected all leases to be released")
+		assert.Equal(t, int64(0), rdb.HLen(ctx, leasedKey(testKey)).Val(), "Expected all leased tasks to be released")
+
+		// A reclaimed delivery can no longer be settled by the worker that abandoned it.
+		assert.ErrorIs(t, deliveries[1].Ack(ctx), ErrDeliveryNotFound, "Expected ErrDeliveryNotFound for a reclaimed delivery")
+	})
+
+	// MultipleConsumers verifies that a single pass reclaims the tasks abandoned by several consumers,
+	// whose processing lists are all declared to the script, and discards leases that cannot be read.
+	t.Run("MultipleConsumers", func(t *testing.T) {
+		testKey := "fetcher.domain.com::reaper_consumers"
+		consumers := []string{"crashed-worker-a", "crashed-worker-b"}
+		assert.NoError(t, rdb.Del(ctx, testKey, leasesKey(testKey), leasedKey(testKey), processingKey(testKey, consumers[0]), processingKey(testKey, consumers[1])).Err(), "Failed to clean up Redis keys")
+
+		for i, consumer := range consumers {
+			taskJSON, _ := transcoder.Encode(TestTask{ID: i + 1})
+			assert.NoError(t, rdb.RPush(ctx, testKey, taskJSON).Err(), "Failed to push task into Redis")
+
+			consumerFetcher, consumerErr := NewReliableFetcher[TestTask](WithClient[TestTask](rdb), WithConsumer[TestTask](consumer), WithVisibilityTimeout[TestTask](time.Millisecond))
+			assert.NoError(t, consumerErr, "Failed to create reliable fetcher")
+
+			deliveries, fetchErr := consumerFetcher.Fetch(ctx, []string{testKey})
+			assert.NoError(t, fetchErr, "Failed to fetch tasks")
+			assert.Len(t, deliveries, 1, "Fetched task count mismatch")
+		}
+
+		assert.NoError(t, rdb.ZAdd(ctx, leasesKey(testKey), redis.Z{Score: 0, Member: "not a lease"}).Err(), "Failed to add malformed lease")
+		time.Sleep(5 * time.Millisecond)
+
+		reclaimed, reapErr := fetcher.Reap(ctx, []string{testKey})
+		assert.NoError(t, reapErr, "Failed to reap expired leases")
+		assert.Equal(t, 2, reclaimed, "Expected the tasks of both consumers to be reclaimed")
+		assert.Equal(t, int64(2), rdb.LLen(ctx, testKey).Val(), "Expected both tasks back in the source list")
+		assert.Equal(t, int64(0), rdb.ZCard(ctx, leasesKey(testKey)).Val(), "Expected all leases, including the malformed one, to be dropped")
+	})
+
+	// StartReaper verifies that the background routine reclaims tasks and stops with its context.
+	t.Run("StartReaper", func(t *testing.T) {
+		testKey := "fetcher.domain.com::reaper_background"
+		// Remove leftovers of previous runs so that the test always starts from an empty list.
+		assert.NoError(t, rdb.Del(ctx, testKey, processingKey(testKey, fetcher.consumer), leasesKey(testKey), leasedKey(testKey)).Err(), "Failed to clean up Redis keys")
+
+		taskJSON, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		assert.NoError(t, rdb.RPush(ctx, testKey, taskJSON).Err(), "Failed to push task into Redis")
+
+		deliveries, fetchErr := fetcher.Fetch(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, deliveries, 1, "Fetched task count mismatch")
+
+		reaperCtx, cancel := context.WithCancel(ctx)
+		done := fetcher.StartReaper(reaperCtx, []string{testKey})
+
+		assert.Eventually(t, func() bool {
+			return rdb.LLen(ctx, testKey).Val() == 1
+		}, time.Second, 10*time.Millisecond, "Expected the reaper to reclaim the abandoned task")
+
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the reaper to stop after its context was canceled")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The script requeueCommand is a Lua script that returns tasks to the head of their source lists in a single atomic step.
+// KEYS holds the source list of every task and ARGV holds the raw tasks in the same order. Tasks are pushed
+// from the last to the first, so the tasks of every list end up at its head in their original order
+// and are fetched again before anything that was pushed in the meantime.
+var requeueCommand = redis.NewScript(`
+for i = #KEYS, 1, -1 do
+	redis.call('LPUSH', KEYS[i], ARGV[i])
+end
+
+return #KEYS
+`)
+
+// Requeue method returns the tasks of the envelopes to the head of their source lists unchanged.
+// It is meant for tasks that were fetched but could not be handled, for instance because the process is
+// shutting down, so they are fetched again by the next consumer in the order they were originally fetched.
+// Envelopes of a single hash slot are pushed atomically, so on a standalone client the whole batch is.
+func (f *RedisFetcher[T]) Requeue(ctx context.Context, envelopes []Envelope[T]) error {
+	return f.requeue(ctx, envelopes)
+}
+
+// requeue method pushes the raw tasks of the envelopes back to their source lists, grouped by hash slot in a cluster.
+func (c *config[T]) requeue(ctx context.Context, envelopes []Envelope[T]) error {
+	if len(envelopes) == 0 {
+		return nil
+	}
+
+	if !c.cluster {
+		return requeueGroup(ctx, c.rdb, envelopes)
+	}
+
+	groups := make(map[int][]Envelope[T])
+	order := make([]int, 0)
+
+	for _, envelope := range envelopes {
+		k := slot(envelope.Key)
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+
+		groups[k] = append(groups[k], envelope)
+	}
+
+	for _, k := range order {
+		if err := requeueGroup(ctx, c.rdb, groups[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requeueGroup function pushes the raw tasks of the envelopes back in a single invocation of the requeue script.
+func requeueGroup[T any](ctx context.Context, rdb redis.UniversalClient, envelopes []Envelope[T]) error {
+	keys := make([]string, 0, len(envelopes))
+	args := make([]interface{}, 0, len(envelopes))
+
+	for _, envelope := range envelopes {
+		keys = append(keys, envelope.Key)
+		args = append(args, envelope.Raw)
+	}
+
+	return requeueCommand.Run(ctx, rdb, keys, args...).Err()
+}
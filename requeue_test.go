@@ -0,0 +1,128 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequeue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// push is a helper that fills the list with tasks numbered from 1 to count.
+	push := func(t *testing.T, key string, count int) {
+		t.Helper()
+
+		assert.NoError(t, rdb.Del(ctx, key).Err(), "Failed to clean up Redis keys")
+
+		for i := 1; i <= count; i++ {
+			taskJSON, _ := transcoder.Encode(TestTask{ID: i})
+			assert.NoError(t, rdb.RPush(ctx, key, taskJSON).Err(), "Failed to push task into Redis")
+		}
+	}
+
+	// ids is a helper that returns the identifiers of the tasks left in the list.
+	ids := func(t *testing.T, key string) []int {
+		t.Helper()
+
+		result := make([]int, 0)
+		for _, raw := range rdb.LRange(ctx, key, 0, -1).Val() {
+			task, err := transcoder.Decode(raw)
+			assert.NoError(t, err, "Failed to decode task left in Redis")
+			result = append(result, task.ID)
+		}
+
+		return result
+	}
+
+	// RequeueToHead verifies that envelopes are returned to the head of their lists in their original order,
+	// ahead of the tasks that were never fetched, while undecodable tasks are left out of the envelopes.
+	t.Run("RequeueToHead", func(t *testing.T) {
+		testKey := "fetcher.domain.com::requeue_head"
+		push(t, testKey, 4)
+		assert.NoError(t, rdb.LPush(ctx, testKey, "not json").Err(), "Failed to push malformed task")
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb), WithTaskSize[TestTask](3))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		envelopes, fetchErr := fetcher.FetchEnvelopes(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch envelopes")
+		assert.Len(t, envelopes, 2, "Expected only the decodable tasks")
+		assert.Equal(t, TestTask{ID: 1}, envelopes[0].Task, "Expected the decoded task in the envelope")
+		assert.Equal(t, 2, envelopes[1].Index, "Expected the batch position to skip the undecodable task")
+
+		assert.NoError(t, fetcher.Requeue(ctx, envelopes), "Failed to requeue envelopes")
+		assert.Equal(t, []int{1, 2, 3, 4}, ids(t, testKey), "Expected the requeued tasks at the head in their original order")
+	})
+
+	// RunnerShutdown verifies that the runner returns the tasks it did not get to handle when it is stopped.
+	t.Run("RunnerShutdown", func(t *testing.T) {
+		testKey := "fetcher.domain.com::requeue_runner"
+		push(t, testKey, 5)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		runCtx, cancel := context.WithCancel(ctx)
+		handled := make([]int, 0)
+
+		runner, err := NewRunner[TestTask](fetcher, []string{testKey}, func(_ context.Context, task TestTask) error {
+			handled = append(handled, task.ID)
+			cancel()
+
+			// Holding the only worker slot a little longer guarantees that the runner observes the shutdown first.
+			time.Sleep(20 * time.Millisecond)
+
+			return nil
+		})
+		assert.NoError(t, err, "Failed to create runner")
+
+		assert.NoError(t, runner.Run(runCtx), "Expected the runner to stop without errors")
+		assert.Equal(t, []int{1}, handled, "Expected only the first task to be handled")
+		assert.Equal(t, []int{2, 3, 4, 5}, ids(t, testKey), "Expected the unhandled tasks to be returned in order")
+	})
+
+	// RunnerDrainExpired verifies that tasks whose handlers are canceled by the drain timeout
+	// are returned to their source lists instead of being lost.
+	t.Run("RunnerDrainExpired", func(t *testing.T) {
+		testKey := "fetcher.domain.com::requeue_drain_expired"
+		push(t, testKey, 3)
+
+		fetcher, err := NewRedisFetcher[TestTask](WithClient[TestTask](rdb))
+		assert.NoError(t, err, "Failed to create redis fetcher")
+
+		runCtx, cancel := context.WithCancel(ctx)
+		var failed atomic.Int32
+
+		runner, err := NewRunner[TestTask](fetcher, []string{testKey}, func(handlerCtx context.Context, _ TestTask) error {
+			cancel()
+			<-handlerCtx.Done()
+
+			return handlerCtx.Err()
+		}, WithDrainTimeout[TestTask](20*time.Millisecond), WithErrorHook[TestTask](func(TestTask, error) {
+			failed.Add(1)
+		}))
+		assert.NoError(t, err, "Failed to create runner")
+
+		assert.NoError(t, runner.Run(runCtx), "Expected the runner to stop without errors")
+		assert.Equal(t, []int{1, 2, 3}, ids(t, testKey), "Expected the interrupted task to be returned ahead of the others")
+		assert.Zero(t, failed.Load(), "Expected the requeued task not to be reported as failed")
+	})
+}
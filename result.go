@@ -0,0 +1,73 @@
+package fetcher
+
+import "fmt"
+
+// Result struct holds the outcome of a single fetch operation.
+// It contains every successfully decoded task together with the tasks that failed decoding,
+// so callers can observe poison messages instead of having them silently dropped.
+type Result[T any] struct {
+	// Tasks holds the successfully decoded tasks in the order they were extracted.
+	Tasks []T
+	// Failures holds the tasks that could not be decoded by the transcoder.
+	Failures []DecodeFailure
+}
+
+// DecodeFailure struct describes a single task that could not be decoded by the transcoder.
+// It implements the error interface and unwraps to the error returned by the transcoder.
+type DecodeFailure struct {
+	// Raw holds the payload exactly as it was extracted from redis.
+	Raw string
+	// Key holds the name of the source key the task was taken from.
+	Key string
+	// Index holds the position of the task within the extracted batch.
+	Index int
+	// Err holds the error returned by the transcoder.
+	Err error
+}
+
+// Error method returns a description of the failure including its source key and batch position.
+func (f DecodeFailure) Error() string {
+	return fmt.Sprintf("decode task %d from %q: %v", f.Index, f.Key, f.Err)
+}
+
+// Unwrap method returns the error reported by the transcoder, allowing inspection with errors.Is and errors.As.
+func (f DecodeFailure) Unwrap() error {
+	return f.Err
+}
+
+// decode method converts the extracted entries into a Result using the configured transcoder.
+// Every failure is reported through the configured logger in addition to being recorded in the result.
+func (c *config[T]) decode(entries []entry) *Result[T] {
+	result := &Result[T]{Tasks: make([]T, 0, len(entries))}
+
+	for i, e := range entries {
+		task, err := c.decodeEntry(e)
+		if err != nil {
+			failure := DecodeFailure{Raw: e.raw, Key: e.key, Index: i, Err: err}
+			c.logFailure(failure)
+			result.Failures = append(result.Failures, failure)
+
+			continue
+		}
+
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	return result
+}
+
+// decodeEntry method decodes the raw task of the entry with the configured transcoder.
+// Entries that could not be read from the script reply report the reason instead of being decoded.
+func (c *config[T]) decodeEntry(e entry) (T, error) {
+	if e.err != nil {
+		var zero T
+		return zero, e.err
+	}
+
+	return c.transcoder.Decode(e.raw)
+}
+
+// logFailure method reports a task that could not be decoded through the configured logger.
+func (c *config[T]) logFailure(failure DecodeFailure) {
+	c.logger.Warn().Err(failure.Err).Str("key", failure.Key).Int("index", failure.Index).Msg("failed to decode task")
+}
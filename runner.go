@@ -0,0 +1,353 @@
+package fetcher
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Handler type defines the function a Runner invokes for every fetched task.
+// A returned error is reported through the error hook of the runner; the task is not fetched again,
+// unless the handler was interrupted by the drain timeout of a runner whose fetcher can requeue it.
+type Handler[T any] func(ctx context.Context, task T) error
+
+// Backoff type defines the policy a Runner uses to pause between polls that returned no tasks.
+// It receives the number of consecutive empty or failed polls, starting at one, and returns the pause before the next poll.
+type Backoff func(attempt int) time.Duration
+
+// ExponentialBackoff function returns a Backoff doubling the pause after every empty poll.
+// The pause starts at initial and never exceeds limit, and is reset as soon as a poll returns tasks.
+func ExponentialBackoff(initial, limit time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		pause := initial
+		for i := 1; i < attempt && pause < limit; i++ {
+			pause *= 2
+		}
+
+		if pause > limit {
+			return limit
+		}
+
+		return pause
+	}
+}
+
+// defaultConcurrency defines the number of tasks a Runner handles at the same time when no limit is configured.
+const defaultConcurrency = 1
+
+// runnerOptions type defines the functional options used to configure a Runner.
+type runnerOptions[T any] func(r *Runner[T])
+
+// Runner struct drives any Fetcher and dispatches the fetched tasks to a handler.
+// It polls the fetcher, fans the tasks out to a bounded number of goroutines, backs off while the keys are empty
+// and drains gracefully when stopped, so services do not have to reimplement the same loop around Fetch.
+// Handler errors and panics are reported through hooks and never stop the runner.
+type Runner[T any] struct {
+	fetcher      Fetcher[T]
+	requeuer     RequeueFetcher[T]
+	observer     Observer
+	keys         []string
+	handler      Handler[T]
+	concurrency  int
+	backoff      Backoff
+	drainTimeout time.Duration
+	onError      func(task T, err error)
+	onPanic      func(task T, recovered interface{})
+	onFetchError func(err error)
+}
+
+// NewRunner function constructs a Runner handling the tasks fetched from keys with the handler.
+// If no options are provided, tasks are handled one at a time and empty polls back off exponentially
+// from 50 milliseconds up to 5 seconds. The function returns an error when the fetcher or the handler is missing.
+func NewRunner[T any](fetcher Fetcher[T], keys []string, handler Handler[T], opts ...runnerOptions[T]) (*Runner[T], error) {
+	if fetcher == nil {
+		return nil, ErrEmptyFetcher
+	}
+
+	if handler == nil {
+		return nil, ErrEmptyHandler
+	}
+
+	r := &Runner[T]{fetcher: fetcher, keys: keys, handler: handler}
+	r.requeuer, _ = fetcher.(RequeueFetcher[T])
+	r.observer, _ = fetcher.(Observer)
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.concurrency <= 0 {
+		r.concurrency = defaultConcurrency
+	}
+
+	if r.backoff == nil {
+		r.backoff = ExponentialBackoff(50*time.Millisecond, 5*time.Second)
+	}
+
+	return r, nil
+}
+
+// Run method polls the fetcher and handles the fetched tasks until ctx is canceled.
+// A new batch is only fetched once every task of the previous batch has been handed to a worker,
+// so at most one batch waits for free workers at any time. When ctx is canceled, no further batch is fetched
+// and the method waits for every running handler to return. Tasks of the current batch that have not been
+// handed to a worker yet are returned to the head of their source lists when the fetcher implements
+// RequeueFetcher, and are still handled otherwise, so that no fetched task is lost.
+// Handlers run with a context that outlives ctx, which is only canceled once the drain timeout, if any,
+// has expired after ctx was canceled. From then on no further task is handed to a worker, and the tasks
+// left over are reported through the error hook with ErrDrainExpired. Handlers that fail once their context
+// has been canceled this way have their tasks returned to the source lists before the method returns when the
+// fetcher implements RequeueFetcher, instead of being reported through the error hook.
+// The method returns nil once the runner has drained.
+func (r *Runner[T]) Run(ctx context.Context) error {
+	handlerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	drained := make(chan struct{})
+	defer close(drained)
+
+	if r.drainTimeout > 0 {
+		go r.expire(ctx, drained, cancel)
+	}
+
+	// Runners that can requeue stop dispatching as soon as they are stopped, the others once the drain has expired.
+	abort := handlerCtx.Done()
+	if r.requeuer != nil {
+		abort = ctx.Done()
+	}
+
+	slots := make(chan struct{}, r.concurrency)
+	var wg sync.WaitGroup
+
+	// interrupted collects the envelopes whose handlers failed after the drain timeout canceled them.
+	var mu sync.Mutex
+	var interrupted []Envelope[T]
+
+	for attempt := 0; ctx.Err() == nil; {
+		batch, err := r.poll(ctx)
+		if err != nil && ctx.Err() == nil && r.onFetchError != nil {
+			r.onFetchError(err)
+		}
+
+		if len(batch) == 0 {
+			attempt++
+			r.sleep(ctx, r.backoff(attempt))
+
+			continue
+		}
+
+		attempt = 0
+		started := time.Now()
+
+		var pending sync.WaitGroup
+		dispatched := 0
+
+		for i, envelope := range batch {
+			if !r.acquire(abort, slots) {
+				r.abandon(ctx, batch[i:])
+				break
+			}
+
+			wg.Add(1)
+			pending.Add(1)
+			dispatched++
+
+			go func(envelope Envelope[T]) {
+				defer wg.Done()
+				defer pending.Done()
+				defer func() { <-slots }()
+
+				err := r.handle(handlerCtx, envelope.Task)
+				if err == nil {
+					return
+				}
+
+				if r.requeuer != nil && handlerCtx.Err() != nil {
+					mu.Lock()
+					interrupted = append(interrupted, envelope)
+					mu.Unlock()
+
+					return
+				}
+
+				if r.onError != nil {
+					r.onError(envelope.Task, err)
+				}
+			}(envelope)
+		}
+
+		// The handling time of the batch is reported once its last task is done, while the next batch is already running.
+		if r.observer != nil && dispatched > 0 {
+			wg.Add(1)
+
+			go func(tasks int) {
+				defer wg.Done()
+
+				pending.Wait()
+				r.observer.Observe(tasks, time.Since(started))
+			}(dispatched)
+		}
+	}
+
+	wg.Wait()
+
+	if len(interrupted) > 0 {
+		r.requeue(ctx, interrupted)
+	}
+
+	return nil
+}
+
+// expire method cancels the handler context once the drain timeout has passed since ctx was canceled.
+// It returns early when the runner has drained before.
+func (r *Runner[T]) expire(ctx context.Context, drained <-chan struct{}, cancel context.CancelFunc) {
+	select {
+	case <-ctx.Done():
+	case <-drained:
+		return
+	}
+
+	timer := time.NewTimer(r.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		cancel()
+	case <-drained:
+	}
+}
+
+// poll method fetches the next batch, wrapping the tasks of fetchers that cannot requeue in bare envelopes.
+func (r *Runner[T]) poll(ctx context.Context) ([]Envelope[T], error) {
+	if r.requeuer != nil {
+		return r.requeuer.FetchEnvelopes(ctx, r.keys)
+	}
+
+	tasks, err := r.fetcher.Fetch(ctx, r.keys)
+
+	envelopes := make([]Envelope[T], 0, len(tasks))
+	for _, task := range tasks {
+		envelopes = append(envelopes, Envelope[T]{Task: task})
+	}
+
+	return envelopes, err
+}
+
+// acquire method waits for a free worker slot. Waiting is abandoned as soon as abort is closed,
+// in which case the method reports false, so the remaining tasks can be returned or reported instead.
+func (r *Runner[T]) acquire(abort <-chan struct{}, slots chan struct{}) bool {
+	select {
+	case <-abort:
+		return false
+	default:
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-abort:
+		return false
+	}
+}
+
+// abandon method gives up on the envelopes that were fetched but not handed to a worker before the runner stopped.
+// They are returned to their source lists when the fetcher can requeue them, and reported through the error hook otherwise.
+func (r *Runner[T]) abandon(ctx context.Context, envelopes []Envelope[T]) {
+	if r.requeuer != nil {
+		r.requeue(ctx, envelopes)
+		return
+	}
+
+	if r.onError == nil {
+		return
+	}
+
+	for _, envelope := range envelopes {
+		r.onError(envelope.Task, ErrDrainExpired)
+	}
+}
+
+// requeue method returns the unhandled envelopes to their source lists after the runner has been stopped.
+// The push is not bound to ctx, which is already canceled at this point.
+func (r *Runner[T]) requeue(ctx context.Context, envelopes []Envelope[T]) {
+	if err := r.requeuer.Requeue(context.WithoutCancel(ctx), envelopes); err != nil && r.onFetchError != nil {
+		r.onFetchError(err)
+	}
+}
+
+// handle method invokes the handler for a single task and returns its error.
+// A panic is reported through the panic hook and results in a nil error.
+func (r *Runner[T]) handle(ctx context.Context, task T) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil && r.onPanic != nil {
+			r.onPanic(task, recovered)
+		}
+	}()
+
+	return r.handler(ctx, task)
+}
+
+// sleep method pauses the runner for the given duration or until ctx is canceled.
+func (r *Runner[T]) sleep(ctx context.Context, pause time.Duration) {
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// WithConcurrency option configures the maximum number of tasks the Runner handles at the same time.
+// If this option is not provided, tasks are handled one at a time.
+func WithConcurrency[T any](limit int) runnerOptions[T] {
+	return func(r *Runner[T]) {
+		r.concurrency = limit
+	}
+}
+
+// WithBackoff option configures the pause between polls that returned no tasks or failed.
+// If this option is not provided, the pause grows exponentially from 50 milliseconds up to 5 seconds.
+func WithBackoff[T any](backoff Backoff) runnerOptions[T] {
+	return func(r *Runner[T]) {
+		r.backoff = backoff
+	}
+}
+
+// WithDrainTimeout option bounds how long the Runner waits for running handlers once it has been stopped.
+// The timeout starts when the context of Run is canceled. When it expires, the context passed to the handlers
+// is canceled and fetched tasks that have not been handed to a worker yet are reported with ErrDrainExpired;
+// Run still waits for the running handlers to return. Tasks whose handlers fail after being canceled are
+// returned to their source lists when the fetcher implements RequeueFetcher.
+// If this option is not provided, handlers are never canceled.
+func WithDrainTimeout[T any](timeout time.Duration) runnerOptions[T] {
+	return func(r *Runner[T]) {
+		r.drainTimeout = timeout
+	}
+}
+
+// WithErrorHook option registers a function invoked with every task whose handler returned an error.
+// The hook runs on the worker goroutine of the task and must be safe for concurrent use.
+func WithErrorHook[T any](hook func(task T, err error)) runnerOptions[T] {
+	return func(r *Runner[T]) {
+		r.onError = hook
+	}
+}
+
+// WithPanicHook option registers a function invoked with every task whose handler panicked, together with
+// the recovered value. The panic never stops the runner, whether a hook is registered or not.
+// The hook runs on the worker goroutine of the task and must be safe for concurrent use.
+func WithPanicHook[T any](hook func(task T, recovered interface{})) runnerOptions[T] {
+	return func(r *Runner[T]) {
+		r.onPanic = hook
+	}
+}
+
+// WithFetchErrorHook option registers a function invoked with every error returned by the fetcher,
+// including failures to requeue unhandled tasks on shutdown. Failed polls back off like empty ones.
+// Errors caused by stopping the runner are not reported.
+func WithFetchErrorHook[T any](hook func(err error)) runnerOptions[T] {
+	return func(r *Runner[T]) {
+		r.onFetchError = hook
+	}
+}
@@ -0,0 +1,191 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// queueFetcher is an in-memory Fetcher handing out its tasks in batches, used to drive the runner in tests.
+type queueFetcher struct {
+	mu    sync.Mutex
+	tasks []int
+	size  int
+	err   error
+}
+
+// Fetch method returns the next batch of tasks, or the configured error once the tasks are exhausted.
+func (f *queueFetcher) Fetch(_ context.Context, _ []string) ([]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.tasks) == 0 {
+		return nil, f.err
+	}
+
+	n := min(f.size, len(f.tasks))
+	batch := f.tasks[:n]
+	f.tasks = f.tasks[n:]
+
+	return batch, nil
+}
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	// InvalidArguments verifies that the fetcher and the handler are mandatory.
+	t.Run("InvalidArguments", func(t *testing.T) {
+		_, err := NewRunner[int](nil, nil, func(context.Context, int) error { return nil })
+		assert.ErrorIs(t, err, ErrEmptyFetcher, "Expected ErrEmptyFetcher without a fetcher")
+
+		_, err = NewRunner[int](&queueFetcher{}, nil, nil)
+		assert.ErrorIs(t, err, ErrEmptyHandler, "Expected ErrEmptyHandler without a handler")
+	})
+
+	// HandlesEveryTask verifies that every fetched task is handled while the concurrency limit is respected.
+	t.Run("HandlesEveryTask", func(t *testing.T) {
+		fetcher := &queueFetcher{tasks: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, size: 3}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var handled, running, peak atomic.Int64
+
+		runner, err := NewRunner[int](fetcher, []string{"queue"}, func(context.Context, int) error {
+			current := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				seen := peak.Load()
+				if current <= seen || peak.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+
+			if handled.Add(1) == 10 {
+				cancel()
+			}
+
+			return nil
+		}, WithConcurrency[int](2), WithBackoff[int](func(int) time.Duration { return time.Millisecond }))
+		assert.NoError(t, err, "Failed to create runner")
+
+		assert.NoError(t, runner.Run(ctx), "Expected the runner to stop without errors")
+		assert.Equal(t, int64(10), handled.Load(), "Expected every task to be handled")
+		assert.LessOrEqual(t, peak.Load(), int64(2), "Expected the concurrency limit to be respected")
+	})
+
+	// Hooks verifies that handler errors, panics and fetch errors are reported without stopping the runner.
+	t.Run("Hooks", func(t *testing.T) {
+		fetchErr := errors.New("redis unavailable")
+		fetcher := &queueFetcher{tasks: []int{1, 2, 3}, size: 10, err: fetchErr}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var mu sync.Mutex
+		var failed, panicked []int
+
+		runner, err := NewRunner[int](fetcher, nil, func(_ context.Context, task int) error {
+			switch task {
+			case 1:
+				return errors.New("failed")
+			case 2:
+				panic("boom")
+			}
+
+			return nil
+		},
+			WithBackoff[int](func(int) time.Duration { return time.Millisecond }),
+			WithErrorHook[int](func(task int, _ error) {
+				mu.Lock()
+				defer mu.Unlock()
+				failed = append(failed, task)
+			}),
+			WithPanicHook[int](func(task int, recovered interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+				panicked = append(panicked, task)
+				assert.Equal(t, "boom", recovered, "Expected the recovered value to be passed")
+			}),
+			WithFetchErrorHook[int](func(err error) {
+				assert.ErrorIs(t, err, fetchErr, "Expected the fetch error to be passed")
+				cancel()
+			}),
+		)
+		assert.NoError(t, err, "Failed to create runner")
+
+		assert.NoError(t, runner.Run(ctx), "Expected the runner to stop without errors")
+		assert.Equal(t, []int{1}, failed, "Expected the failed task to be reported")
+		assert.Equal(t, []int{2}, panicked, "Expected the panicking task to be reported")
+	})
+
+	// DrainsOnCancel verifies that handlers keep running after cancellation until the drain timeout cancels them.
+	t.Run("DrainsOnCancel", func(t *testing.T) {
+		fetcher := &queueFetcher{tasks: []int{1}, size: 1}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var finished atomic.Bool
+
+		runner, err := NewRunner[int](fetcher, nil, func(handlerCtx context.Context, _ int) error {
+			cancel()
+
+			select {
+			case <-handlerCtx.Done():
+				return handlerCtx.Err()
+			case <-time.After(20 * time.Millisecond):
+				finished.Store(true)
+				return nil
+			}
+		}, WithDrainTimeout[int](time.Second))
+		assert.NoError(t, err, "Failed to create runner")
+
+		assert.NoError(t, runner.Run(ctx), "Expected the runner to stop without errors")
+		assert.True(t, finished.Load(), "Expected the running handler to complete after cancellation")
+	})
+
+	// DrainStartsOnCancel verifies that the drain timeout starts when the runner is stopped rather than
+	// once the batch has been dispatched, and that the tasks left over are reported instead of being handled.
+	t.Run("DrainStartsOnCancel", func(t *testing.T) {
+		fetcher := &queueFetcher{tasks: []int{1, 2, 3}, size: 3}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var handled atomic.Int32
+		var mu sync.Mutex
+		var abandoned []int
+
+		runner, err := NewRunner[int](fetcher, nil, func(handlerCtx context.Context, _ int) error {
+			handled.Add(1)
+			cancel()
+
+			<-handlerCtx.Done()
+
+			return handlerCtx.Err()
+		}, WithDrainTimeout[int](50*time.Millisecond), WithErrorHook[int](func(task int, err error) {
+			if errors.Is(err, ErrDrainExpired) {
+				mu.Lock()
+				abandoned = append(abandoned, task)
+				mu.Unlock()
+			}
+		}))
+		assert.NoError(t, err, "Failed to create runner")
+
+		started := time.Now()
+		assert.NoError(t, runner.Run(ctx), "Expected the runner to stop without errors")
+		assert.Less(t, time.Since(started), time.Second, "Expected the drain timeout to bound the shutdown")
+		assert.Equal(t, int32(1), handled.Load(), "Expected no task to be dispatched after the drain expired")
+		assert.Equal(t, []int{2, 3}, abandoned, "Expected the undispatched tasks to be reported")
+	})
+
+	// Backoff verifies the growth and the cap of the exponential backoff.
+	t.Run("Backoff", func(t *testing.T) {
+		backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+		assert.Equal(t, 10*time.Millisecond, backoff(1), "Expected the initial pause on the first empty poll")
+		assert.Equal(t, 40*time.Millisecond, backoff(3), "Expected the pause to double after every empty poll")
+		assert.Equal(t, 50*time.Millisecond, backoff(10), "Expected the pause to be capped")
+	})
+}
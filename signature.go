@@ -0,0 +1,114 @@
+package fetcher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// SignedCodec is a transcoder that signs the payloads of an inner transcoder with HMAC-SHA256.
+// Payloads are verified before they are handed to the inner transcoder, so tasks that were not written
+// by a trusted producer are rejected as decode failures instead of being executed. Every payload records
+// the identifier of its signing key, which allows several verification keys to be active during a rotation.
+type SignedCodec[T any] struct {
+	inner   Transcoder[T]
+	primary string
+	keys    map[string][]byte
+}
+
+// NewSignedCodec function returns a SignedCodec wrapping the inner transcoder.
+// The keys map key identifiers to secrets accepted for verification, and primary names the key used for signing.
+// Identifiers are stored in every payload and must be between 1 and 255 bytes long, and secrets must not be empty.
+// The function returns ErrInvalidKey when a key is invalid and ErrUnknownKey when the primary key is missing from the keys.
+func NewSignedCodec[T any](inner Transcoder[T], primary string, keys map[string][]byte) (*SignedCodec[T], error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+
+	secrets := make(map[string][]byte, len(keys))
+
+	for id, key := range keys {
+		if len(id) == 0 || len(id) > 255 {
+			return nil, fmt.Errorf("%w: identifier length %d", ErrInvalidKey, len(id))
+		}
+
+		if len(key) == 0 {
+			return nil, fmt.Errorf("%w: key %q has an empty secret", ErrInvalidKey, id)
+		}
+
+		secrets[id] = append([]byte(nil), key...)
+	}
+
+	return &SignedCodec[T]{inner: inner, primary: primary, keys: secrets}, nil
+}
+
+// Encode method encodes the value with the inner transcoder and signs the result.
+func (c *SignedCodec[T]) Encode(src T) (string, error) {
+	bytes, err := c.EncodeBytes(src)
+
+	return string(bytes), err
+}
+
+// Decode method verifies the signature of the payload and decodes it with the inner transcoder.
+func (c *SignedCodec[T]) Decode(src string) (T, error) {
+	return c.DecodeBytes([]byte(src))
+}
+
+// EncodeBytes method encodes the value with the inner transcoder and signs the result with the primary key.
+// The signed payload consists of the key identifier, the signature and the encoded value.
+// The signature covers the key identifier as well, so it cannot be swapped without detection.
+// It returns ErrMissingEncoder when the inner transcoder cannot encode values.
+func (c *SignedCodec[T]) EncodeBytes(src T) ([]byte, error) {
+	encoder, ok := c.inner.(Encoder[T])
+	if !ok {
+		return nil, ErrMissingEncoder
+	}
+
+	body, err := encodeBytes(encoder, src)
+	if err != nil {
+		return nil, err
+	}
+
+	header := appendKeyID(nil, c.primary)
+
+	payload := make([]byte, 0, len(header)+sha256.Size+len(body))
+	payload = append(payload, header...)
+	payload = append(payload, sign(c.keys[c.primary], header, body)...)
+
+	return append(payload, body...), nil
+}
+
+// DecodeBytes method verifies the signature with the key named by the payload and decodes the signed value
+// with the inner transcoder. Payloads signed with a key that is not known are rejected with ErrUnknownKey,
+// while unsigned, malformed or tampered payloads are rejected with ErrInvalidSignature.
+func (c *SignedCodec[T]) DecodeBytes(src []byte) (T, error) {
+	var zero T
+
+	id, rest, ok := splitKeyID(src)
+	if !ok || len(rest) < sha256.Size {
+		return zero, fmt.Errorf("%w: truncated payload", ErrInvalidSignature)
+	}
+
+	key, ok := c.keys[id]
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	header := src[:len(src)-len(rest)]
+	signature, body := rest[:sha256.Size], rest[sha256.Size:]
+
+	if !hmac.Equal(signature, sign(key, header, body)) {
+		return zero, ErrInvalidSignature
+	}
+
+	return decodeBytes(c.inner, body)
+}
+
+// sign function computes the HMAC-SHA256 signature of the header followed by the body.
+func sign(key, header, body []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(header)
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,84 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSignedCodec verifies the round trip of SignedCodec[T] and the rejection of untrusted payloads.
+func TestSignedCodec(t *testing.T) {
+	task := TestTask{ID: 1, Data: "task1"}
+
+	before, err := NewSignedCodec[TestTask](NewJSONCodec[TestTask](), "k1", map[string][]byte{"k1": []byte("old secret")})
+	assert.NoError(t, err, "Failed to create signed codec")
+
+	after, err := NewSignedCodec[TestTask](NewJSONCodec[TestTask](), "k2", map[string][]byte{"k1": []byte("old secret"), "k2": []byte("new secret")})
+	assert.NoError(t, err, "Failed to create signed codec")
+
+	// RoundTrip verifies that signed payloads are verified and decoded by the inner transcoder.
+	t.Run("RoundTrip", func(t *testing.T) {
+		encoded, encodeErr := after.Encode(task)
+		assert.NoError(t, encodeErr, "Encode must succeed for valid Go values")
+
+		decoded, decodeErr := after.Decode(encoded)
+		assert.NoError(t, decodeErr, "Decode must succeed for its own output")
+		assert.Equal(t, task, decoded, "Expected the round trip to preserve the value")
+	})
+
+	// Rotation verifies that payloads signed with any verification key are accepted,
+	// while payloads signed with a key that is not configured are rejected.
+	t.Run("Rotation", func(t *testing.T) {
+		old, _ := before.Encode(task)
+
+		decoded, decodeErr := after.Decode(old)
+		assert.NoError(t, decodeErr, "Expected payloads of a retired key to be accepted")
+		assert.Equal(t, task, decoded, "Unexpected task decoded from a payload of a retired key")
+
+		current, _ := after.Encode(task)
+
+		_, decodeErr = before.Decode(current)
+		assert.ErrorIs(t, decodeErr, ErrUnknownKey, "Expected payloads of an unknown key to be rejected")
+	})
+
+	// Tampering verifies that injected, modified and forged payloads are rejected.
+	t.Run("Tampering", func(t *testing.T) {
+		encoded, _ := after.Encode(task)
+
+		_, decodeErr := after.Decode(encoded[:len(encoded)-2] + `9}`)
+		assert.ErrorIs(t, decodeErr, ErrInvalidSignature, "Expected modified payloads to be rejected")
+
+		_, decodeErr = after.Decode(`{"id":1,"data":"task1"}`)
+		assert.Error(t, decodeErr, "Expected unsigned payloads to be rejected")
+
+		forger, _ := NewSignedCodec[TestTask](NewJSONCodec[TestTask](), "k2", map[string][]byte{"k2": []byte("guessed secret")})
+		forged, _ := forger.Encode(task)
+
+		_, decodeErr = after.Decode(forged)
+		assert.ErrorIs(t, decodeErr, ErrInvalidSignature, "Expected payloads signed with a wrong secret to be rejected")
+
+		_, decodeErr = after.Decode("\x02k2")
+		assert.ErrorIs(t, decodeErr, ErrInvalidSignature, "Expected truncated payloads to be rejected")
+	})
+
+	// InvalidKeys verifies that construction fails for a missing primary key.
+	t.Run("InvalidKeys", func(t *testing.T) {
+		_, constructErr := NewSignedCodec[TestTask](NewJSONCodec[TestTask](), "missing", map[string][]byte{"k1": []byte("secret")})
+		assert.ErrorIs(t, constructErr, ErrUnknownKey, "Expected a missing primary key to be rejected")
+	})
+
+	// InvalidKeyIdentifiers verifies that identifiers that do not fit the payload header and empty secrets
+	// are rejected with ErrInvalidKey.
+	t.Run("InvalidKeyIdentifiers", func(t *testing.T) {
+		_, constructErr := NewSignedCodec[TestTask](NewJSONCodec[TestTask](), "", map[string][]byte{"": []byte("secret")})
+		assert.ErrorIs(t, constructErr, ErrInvalidKey, "Expected an empty key identifier to be rejected")
+
+		long := strings.Repeat("k", 256)
+		_, constructErr = NewSignedCodec[TestTask](NewJSONCodec[TestTask](), long, map[string][]byte{long: []byte("secret")})
+		assert.ErrorIs(t, constructErr, ErrInvalidKey, "Expected a key identifier longer than 255 bytes to be rejected")
+
+		_, constructErr = NewSignedCodec[TestTask](NewJSONCodec[TestTask](), "k1", map[string][]byte{"k1": nil})
+		assert.ErrorIs(t, constructErr, ErrInvalidKey, "Expected an empty secret to be rejected")
+	})
+}
@@ -0,0 +1,316 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultStreamField defines the stream entry field decoded by the StreamFetcher when no field is configured.
+const defaultStreamField = "payload"
+
+// StreamFetcher struct provides a fetcher for tasks stored in Redis Streams and consumed through a consumer group.
+// Entries are read with XREADGROUP on behalf of the configured consumer, so every entry is delivered to a single
+// consumer of the group and stays pending until it is acknowledged with XACK. Stale pending entries of crashed
+// consumers can be claimed with XAUTOCLAIM, which makes the fetcher suitable for at-least-once processing.
+type StreamFetcher[T any] struct {
+	config[T]
+
+	// groups records the streams whose consumer group is known to exist.
+	groups sync.Map
+}
+
+// Message struct represents a stream entry delivered to the consumer together with its decoded task.
+// The identifier must be passed to Ack once the task has been handled.
+type Message[T any] struct {
+	// ID holds the identifier of the stream entry.
+	ID string
+	// Stream holds the name of the stream the entry belongs to.
+	Stream string
+	// Task holds the decoded value of the configured field.
+	Task T
+}
+
+// NewStreamFetcher function constructs a fully configured StreamFetcher instance.
+// It accepts the same functional options as NewRedisFetcher, together with WithGroup, WithField and WithClaimIdle.
+// Options that only apply to lists, such as WithScript or WithStrategy, are ignored,
+// except for WithByteBudget and WithAdaptiveSize, which are rejected with ErrIncompatibleOptions.
+// The function returns an error when the redis client or the consumer group is missing, or options cannot be combined.
+func NewStreamFetcher[T any](opts ...options[T]) (*StreamFetcher[T], error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cfg.listOnly(); err != nil {
+		return nil, err
+	}
+
+	if cfg.group == "" {
+		return nil, ErrEmptyGroup
+	}
+
+	return &StreamFetcher[T]{config: cfg}, nil
+}
+
+// Fetch method reads up to the configured number of entries from the streams identified by keys
+// and acknowledges them right away, which gives the same at-most-once semantics as RedisFetcher.Fetch.
+// All entries are acknowledged in a single pipeline. Should it fail, the tasks are still returned, as they have
+// already been delivered to the consumer, and the failure is logged; the unacknowledged entries stay pending.
+// Use FetchMessages to acknowledge entries only after they have been handled.
+// The method returns a slice of tasks of type T and an error if any occurred during the operation.
+func (f *StreamFetcher[T]) Fetch(ctx context.Context, keys []string) ([]T, error) {
+	messages, err := f.FetchMessages(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]T, 0, len(messages))
+	ids := make(map[string][]string)
+
+	for _, message := range messages {
+		tasks = append(tasks, message.Task)
+		ids[message.Stream] = append(ids[message.Stream], message.ID)
+	}
+
+	if err = f.acknowledge(ctx, ids); err != nil {
+		f.logger.Error().Err(err).Strs("keys", keys).Msg("failed to acknowledge fetched stream entries")
+	}
+
+	return tasks, nil
+}
+
+// FetchMessages method reads up to the configured number of entries from the streams identified by keys.
+// When claiming is enabled, stale pending entries are claimed first, then new entries are read from the streams
+// in the order of keys. Entries that cannot be decoded are acknowledged right away, after being moved to the
+// dead-letter list when one is configured, so they are never delivered again.
+// Entries delivered before a failure stay pending in the group, so they are returned and the failure is logged,
+// like the list fetchers do; the failure is only returned when nothing has been delivered.
+// The method returns the delivered messages, which stay pending until they are acknowledged with Ack.
+func (f *StreamFetcher[T]) FetchMessages(ctx context.Context, keys []string) ([]Message[T], error) {
+	entries := make([]streamEntry, 0)
+
+	for _, stream := range keys {
+		if err := f.ensureGroup(ctx, stream); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.claimIdle > 0 {
+		for _, stream := range keys {
+			remaining := f.size - len(entries)
+			if remaining <= 0 {
+				break
+			}
+
+			claimed, _, err := f.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   stream,
+				Group:    f.group,
+				Consumer: f.consumer,
+				MinIdle:  f.claimIdle,
+				Start:    "0-0",
+				Count:    int64(remaining),
+			}).Result()
+			if err != nil {
+				f.forget(stream, err)
+				return f.interrupted(ctx, keys, entries, err)
+			}
+
+			entries = appendStreamEntries(entries, stream, claimed)
+		}
+	}
+
+	// Streams are read one at a time, as the count of XREADGROUP applies to every stream separately
+	// and reading more entries than requested would leave them pending without ever handing them out.
+	for _, stream := range keys {
+		remaining := f.size - len(entries)
+		if remaining <= 0 {
+			break
+		}
+
+		streams, err := f.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    f.group,
+			Consumer: f.consumer,
+			Streams:  []string{stream, ">"},
+			Count:    int64(remaining),
+			Block:    -1,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		if err != nil {
+			f.forget(stream, err)
+			return f.interrupted(ctx, keys, entries, err)
+		}
+
+		for _, s := range streams {
+			entries = appendStreamEntries(entries, s.Stream, s.Messages)
+		}
+	}
+
+	return f.decodeMessages(ctx, entries)
+}
+
+// Ack method acknowledges the entries identified by ids in the stream, removing them from the pending entries list.
+// It must be called once the tasks of the entries have been handled successfully.
+func (f *StreamFetcher[T]) Ack(ctx context.Context, stream string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	err := f.rdb.XAck(ctx, stream, f.group, ids...).Err()
+	f.forget(stream, err)
+
+	return err
+}
+
+// acknowledge method acknowledges the entries identified by ids, grouped by stream, in a single pipeline.
+func (f *StreamFetcher[T]) acknowledge(ctx context.Context, ids map[string][]string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cmds := make(map[string]*redis.IntCmd, len(ids))
+
+	_, err := f.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for stream, streamIDs := range ids {
+			cmds[stream] = pipe.XAck(ctx, stream, f.group, streamIDs...)
+		}
+
+		return nil
+	})
+
+	for stream, cmd := range cmds {
+		f.forget(stream, cmd.Err())
+	}
+
+	return err
+}
+
+// interrupted method handles a read that failed after entries may already have been delivered to the consumer.
+// The delivered entries are decoded and returned with the failure logged, while the failure itself is only
+// returned when nothing has been delivered.
+func (f *StreamFetcher[T]) interrupted(ctx context.Context, keys []string, entries []streamEntry, err error) ([]Message[T], error) {
+	if len(entries) == 0 {
+		return nil, err
+	}
+
+	f.logger.Error().Err(err).Strs("keys", keys).Msg("failed to read entries from some streams")
+
+	return f.decodeMessages(ctx, entries)
+}
+
+// ensureGroup method creates the consumer group of the stream, together with the stream itself, on first use.
+// The group starts at the beginning of the stream, so entries written before the first fetch are not skipped.
+func (f *StreamFetcher[T]) ensureGroup(ctx context.Context, stream string) error {
+	if _, ok := f.groups.Load(stream); ok {
+		return nil
+	}
+
+	err := f.rdb.XGroupCreateMkStream(ctx, stream, f.group, "0").Err()
+	if err != nil && !redis.HasErrorPrefix(err, "BUSYGROUP") {
+		return err
+	}
+
+	f.groups.Store(stream, struct{}{})
+
+	return nil
+}
+
+// forget method evicts the consumer group of the stream from the cache when err reports that it no longer exists,
+// which happens once the stream has been deleted, so the group is created again on the next fetch.
+func (f *StreamFetcher[T]) forget(stream string, err error) {
+	if redis.HasErrorPrefix(err, "NOGROUP") {
+		f.groups.Delete(stream)
+	}
+}
+
+// streamEntry struct pairs a raw stream entry with the stream it was read from.
+type streamEntry struct {
+	stream  string
+	message redis.XMessage
+}
+
+// appendStreamEntries function appends the messages of the stream to the entries.
+func appendStreamEntries(entries []streamEntry, stream string, messages []redis.XMessage) []streamEntry {
+	for _, message := range messages {
+		entries = append(entries, streamEntry{stream: stream, message: message})
+	}
+
+	return entries
+}
+
+// decodeMessages method decodes the configured field of every entry and settles the entries that failed decoding.
+func (f *StreamFetcher[T]) decodeMessages(ctx context.Context, entries []streamEntry) ([]Message[T], error) {
+	messages := make([]Message[T], 0, len(entries))
+	rejected := make([]streamEntry, 0)
+	records := make([]interface{}, 0)
+
+	for i, e := range entries {
+		raw, ok := e.message.Values[f.field].(string)
+
+		var task T
+		var err error
+
+		if ok {
+			task, err = f.transcoder.Decode(raw)
+		} else {
+			err = fmt.Errorf("%w: %q", ErrMissingField, f.field)
+		}
+
+		if err != nil {
+			f.logFailure(DecodeFailure{Raw: raw, Key: e.stream, Index: i, Err: err})
+
+			if f.deadLetter != "" {
+				record, recordErr := newDeadLetterRecord(entry{key: e.stream, raw: raw}, err)
+				if recordErr != nil {
+					return nil, recordErr
+				}
+
+				records = append(records, record)
+			}
+
+			rejected = append(rejected, e)
+			continue
+		}
+
+		messages = append(messages, Message[T]{ID: e.message.ID, Stream: e.stream, Task: task})
+	}
+
+	if len(rejected) == 0 {
+		return messages, nil
+	}
+
+	// Rejected entries are dead-lettered before they are acknowledged, and only once the dead-letter list
+	// has accepted them. Should either step fail, the entries stay pending and are claimed again,
+	// so no payload is ever lost.
+	if err := f.settleRejected(ctx, rejected, records); err != nil {
+		if len(messages) == 0 {
+			return nil, err
+		}
+
+		f.logger.Error().Err(err).Msg("failed to settle undecodable stream entries")
+	}
+
+	return messages, nil
+}
+
+// settleRejected method moves the records of the rejected entries to the dead-letter list and acknowledges them.
+func (f *StreamFetcher[T]) settleRejected(ctx context.Context, rejected []streamEntry, records []interface{}) error {
+	if len(records) > 0 {
+		if err := f.rdb.RPush(ctx, f.deadLetter, records...).Err(); err != nil {
+			return err
+		}
+	}
+
+	ids := make(map[string][]string)
+	for _, e := range rejected {
+		ids[e.stream] = append(ids[e.stream], e.message.ID)
+	}
+
+	return f.acknowledge(ctx, ids)
+}
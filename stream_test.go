@@ -0,0 +1,207 @@
+package fetcher
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamFetcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	redisAddress := os.Getenv("REDIS_ADDRESS")
+
+	// Retrieve the Redis client used to interact with the Redis instance of the test environment.
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{redisAddress}})
+	defer rdb.Close()
+
+	assert.NoError(t, rdb.Ping(ctx).Err(), "Expected Redis server to respond to ping without errors")
+
+	transcoder := &JSONCodec[TestTask]{}
+
+	// add is a helper that appends tasks to the stream under the default payload field.
+	add := func(t *testing.T, stream string, tasks ...TestTask) {
+		t.Helper()
+
+		for _, task := range tasks {
+			taskJSON, _ := transcoder.Encode(task)
+			assert.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: map[string]interface{}{"payload": taskJSON}}).Err(), "Failed to add task")
+		}
+	}
+
+	// EmptyGroup verifies that the consumer group is mandatory.
+	t.Run("EmptyGroup", func(t *testing.T) {
+		_, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb))
+		assert.ErrorIs(t, err, ErrEmptyGroup, "Expected ErrEmptyGroup without a consumer group")
+	})
+
+	// FetchMessages verifies that the group is created on first use and that delivered entries
+	// stay pending until they are acknowledged.
+	t.Run("FetchMessages", func(t *testing.T) {
+		testKey := "fetcher.domain.com::stream_messages"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		fetcher, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithConsumer[TestTask]("worker-1"), WithTaskSize[TestTask](2))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		// The group is created before the entries are added, which must not skip them.
+		messages, fetchErr := fetcher.FetchMessages(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch from an empty stream")
+		assert.Empty(t, messages, "Expected no messages from an empty stream")
+
+		add(t, testKey, TestTask{ID: 1, Data: "task1"}, TestTask{ID: 2, Data: "task2"}, TestTask{ID: 3, Data: "task3"})
+
+		messages, fetchErr = fetcher.FetchMessages(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, messages, 2, "Expected the task size to be respected")
+		assert.Equal(t, TestTask{ID: 1, Data: "task1"}, messages[0].Task, "Expected entries in stream order")
+		assert.Equal(t, testKey, messages[0].Stream, "Expected the stream to be recorded")
+
+		pending := rdb.XPending(ctx, testKey, "workers").Val()
+		assert.Equal(t, int64(2), pending.Count, "Expected delivered entries to stay pending")
+
+		assert.NoError(t, fetcher.Ack(ctx, testKey, messages[0].ID, messages[1].ID), "Failed to acknowledge messages")
+		assert.Equal(t, int64(0), rdb.XPending(ctx, testKey, "workers").Val().Count, "Expected no pending entries after acknowledgement")
+	})
+
+	// FetchAcknowledges verifies that Fetch reads from every stream and acknowledges right away.
+	t.Run("FetchAcknowledges", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::stream_fetch_high", "fetcher.domain.com::stream_fetch_low"}
+		assert.NoError(t, rdb.Del(ctx, keys...).Err(), "Failed to clean up Redis keys")
+
+		add(t, keys[0], TestTask{ID: 1, Data: "high"})
+		add(t, keys[1], TestTask{ID: 2, Data: "low"})
+
+		fetcher, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Equal(t, []TestTask{{ID: 1, Data: "high"}, {ID: 2, Data: "low"}}, tasks, "Expected tasks of every stream in key order")
+
+		for _, key := range keys {
+			assert.Equal(t, int64(0), rdb.XPending(ctx, key, "workers").Val().Count, "Expected fetched entries to be acknowledged")
+		}
+	})
+
+	// ClaimsStaleEntries verifies that entries left pending by another consumer are claimed once idle long enough.
+	t.Run("ClaimsStaleEntries", func(t *testing.T) {
+		testKey := "fetcher.domain.com::stream_claim"
+		assert.NoError(t, rdb.Del(ctx, testKey).Err(), "Failed to clean up Redis keys")
+
+		crashed, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithConsumer[TestTask]("crashed"))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		_, fetchErr := crashed.FetchMessages(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to create the consumer group")
+
+		add(t, testKey, TestTask{ID: 1, Data: "stale"})
+
+		messages, fetchErr := crashed.FetchMessages(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, messages, 1, "Expected the entry to be delivered to the crashed consumer")
+
+		time.Sleep(20 * time.Millisecond)
+
+		fetcher, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithConsumer[TestTask]("healthy"), WithClaimIdle[TestTask](10*time.Millisecond))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		claimed, fetchErr := fetcher.FetchMessages(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to claim tasks")
+		assert.Len(t, claimed, 1, "Expected the stale entry to be claimed")
+		assert.Equal(t, messages[0].ID, claimed[0].ID, "Expected the same entry to be redelivered")
+	})
+
+	// RoutesUndecodable verifies that entries which cannot be decoded are dead-lettered and acknowledged.
+	t.Run("RoutesUndecodable", func(t *testing.T) {
+		testKey := "fetcher.domain.com::stream_dead_letter"
+		deadLetterKey := "fetcher.domain.com::stream_dead_letter_dlq"
+		assert.NoError(t, rdb.Del(ctx, testKey, deadLetterKey).Err(), "Failed to clean up Redis keys")
+
+		fetcher, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithField[TestTask]("body"), WithDeadLetter[TestTask](deadLetterKey))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		valid, _ := transcoder.Encode(TestTask{ID: 1, Data: "task1"})
+		assert.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{Stream: testKey, Values: map[string]interface{}{"body": "not json"}}).Err(), "Failed to add task")
+		assert.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{Stream: testKey, Values: map[string]interface{}{"other": valid}}).Err(), "Failed to add task")
+		assert.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{Stream: testKey, Values: map[string]interface{}{"body": valid}}).Err(), "Failed to add task")
+
+		messages, fetchErr := fetcher.FetchMessages(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Failed to fetch tasks")
+		assert.Len(t, messages, 1, "Expected only the decodable entry to be delivered")
+		assert.Equal(t, int64(2), rdb.LLen(ctx, deadLetterKey).Val(), "Expected both undecodable entries to be dead-lettered")
+		assert.Equal(t, int64(1), rdb.XPending(ctx, testKey, "workers").Val().Count, "Expected only the delivered entry to stay pending")
+	})
+
+	// KeepsPendingOnDeadLetterFailure verifies that undecodable entries are not acknowledged
+	// when the dead-letter list rejects them, so they can be claimed again instead of being lost.
+	t.Run("KeepsPendingOnDeadLetterFailure", func(t *testing.T) {
+		testKey := "fetcher.domain.com::stream_dead_letter_failure"
+		deadLetterKey := "fetcher.domain.com::stream_dead_letter_failure_dlq"
+		assert.NoError(t, rdb.Del(ctx, testKey, deadLetterKey).Err(), "Failed to clean up Redis keys")
+
+		// A string stored under the dead-letter key makes every RPUSH to it fail.
+		assert.NoError(t, rdb.Set(ctx, deadLetterKey, "occupied", 0).Err(), "Failed to occupy the dead-letter key")
+
+		fetcher, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithDeadLetter[TestTask](deadLetterKey))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		assert.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{Stream: testKey, Values: map[string]interface{}{"payload": "not json"}}).Err(), "Failed to add task")
+
+		_, fetchErr := fetcher.FetchMessages(ctx, []string{testKey})
+		assert.Error(t, fetchErr, "Expected the dead-letter failure to be returned")
+		assert.Equal(t, int64(1), rdb.XPending(ctx, testKey, "workers").Val().Count, "Expected the undecodable entry to stay pending")
+	})
+
+	// ReturnsDeliveredOnDeadLetterFailure verifies that decoded messages are still returned
+	// when the dead-letter list rejects the undecodable entries of the same batch.
+	t.Run("ReturnsDeliveredOnDeadLetterFailure", func(t *testing.T) {
+		testKey := "fetcher.domain.com::stream_delivered_dead_letter_failure"
+		deadLetterKey := "fetcher.domain.com::stream_delivered_dead_letter_failure_dlq"
+		assert.NoError(t, rdb.Del(ctx, testKey, deadLetterKey).Err(), "Failed to clean up Redis keys")
+		assert.NoError(t, rdb.Set(ctx, deadLetterKey, "occupied", 0).Err(), "Failed to occupy the dead-letter key")
+
+		fetcher, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithDeadLetter[TestTask](deadLetterKey))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		assert.NoError(t, rdb.XAdd(ctx, &redis.XAddArgs{Stream: testKey, Values: map[string]interface{}{"payload": "not json"}}).Err(), "Failed to add task")
+		add(t, testKey, TestTask{ID: 1})
+
+		messages, fetchErr := fetcher.FetchMessages(ctx, []string{testKey})
+		assert.NoError(t, fetchErr, "Expected the dead-letter failure to be logged")
+		assert.Len(t, messages, 1, "Expected the decoded message to be returned")
+		assert.Equal(t, int64(2), rdb.XPending(ctx, testKey, "workers").Val().Count, "Expected both entries to stay pending")
+	})
+
+	// RecreatesDeletedGroup verifies that messages read before a failing stream are returned,
+	// and that a consumer group removed from the server is created again on the next fetch.
+	t.Run("RecreatesDeletedGroup", func(t *testing.T) {
+		keys := []string{"fetcher.domain.com::stream_deleted_group_first", "fetcher.domain.com::stream_deleted_group_second"}
+		assert.NoError(t, rdb.Del(ctx, keys...).Err(), "Failed to clean up Redis keys")
+
+		fetcher, err := NewStreamFetcher[TestTask](WithClient[TestTask](rdb), WithGroup[TestTask]("workers"), WithTaskSize[TestTask](10))
+		assert.NoError(t, err, "Failed to create stream fetcher")
+
+		_, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Failed to create the consumer groups")
+
+		assert.NoError(t, rdb.XGroupDestroy(ctx, keys[1], "workers").Err(), "Failed to destroy the consumer group")
+		add(t, keys[0], TestTask{ID: 1}, TestTask{ID: 2})
+
+		tasks, fetchErr := fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Expected the missing group to be logged")
+		assert.Len(t, tasks, 2, "Expected the tasks of the first stream to be returned")
+
+		add(t, keys[1], TestTask{ID: 3})
+
+		tasks, fetchErr = fetcher.Fetch(ctx, keys)
+		assert.NoError(t, fetchErr, "Expected the consumer group to be created again")
+		assert.Len(t, tasks, 1, "Expected the task of the recreated group to be returned")
+	})
+}
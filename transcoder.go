@@ -14,22 +14,47 @@ type Transcoder[T any] interface {
 	Decode(string) (T, error)
 }
 
-// defaultTranscoder is the built-in transcoder used when the user does not provide a custom one.
+// Encoder defines the interface for encoding values of type T into their string representation.
+// It is the counterpart of Transcoder and is required by the RedisPublisher to produce tasks.
+// Transcoders that implement both interfaces guarantee that producers and consumers agree on the format.
+type Encoder[T any] interface {
+	// Encode serializes a value of type T into a string that Decode of the same transcoder can reverse.
+	Encode(T) (string, error)
+}
+
+// Codec defines the full bidirectional interface for serializing values of type T.
+// It combines Transcoder and Encoder, so a single Codec can be shared by fetchers and publishers,
+// and adds byte-slice variants that let callers working with raw bytes avoid intermediate string copies.
+// Every Codec is accepted by WithTranscoder, and wrapping transcoders are built around this interface.
+type Codec[T any] interface {
+	Transcoder[T]
+	Encoder[T]
+
+	// EncodeBytes serializes a value of type T into bytes that DecodeBytes of the same codec can reverse.
+	EncodeBytes(T) ([]byte, error)
+	// DecodeBytes reconstructs a value of type T from the bytes previously produced by EncodeBytes.
+	DecodeBytes([]byte) (T, error)
+}
+
+// JSONCodec is the built-in transcoder used when the user does not provide a custom one.
 // It performs straightforward JSON serialization with no additional compression.
 // This makes payloads human-readable and is perfect for development, debugging,
 // or situations where size is not a critical concern.
 // Users who need smaller storage footprint or a different format should supply their own transcoder.
-type defaultTranscoder[T any] struct{}
+type JSONCodec[T any] struct{}
+
+// NewJSONCodec function returns a JSONCodec for values of type T.
+// The codec is stateless, so a single instance can be shared freely between fetchers, publishers and goroutines.
+func NewJSONCodec[T any]() *JSONCodec[T] {
+	return &JSONCodec[T]{}
+}
 
 // Encode method converts the provided value into a JSON string representation.
 // Method serializes the input value into bytes using JSON encoding and then converts those bytes into a string.
 // Any error produced during the serialization process is returned to the caller for handling.
 // This method ensures that values can be safely stored in systems that expect string data.
-func (defaultTranscoder[T]) Encode(src T) (string, error) {
-	var bytes []byte
-	var err error
-
-	bytes, err = json.Marshal(src)
+func (c JSONCodec[T]) Encode(src T) (string, error) {
+	bytes, err := c.EncodeBytes(src)
 
 	return string(bytes), err
 }
@@ -38,12 +63,46 @@ func (defaultTranscoder[T]) Encode(src T) (string, error) {
 // Method converts the string back into bytes and uses JSON decoding to populate the target value.
 // Any error encountered during decoding is returned to the caller for proper handling.
 // This method ensures that stored string data can be converted back into a usable typed value.
-func (defaultTranscoder[T]) Decode(src string) (T, error) {
+func (c JSONCodec[T]) Decode(src string) (T, error) {
+	return c.DecodeBytes([]byte(src))
+}
+
+// EncodeBytes method converts the provided value into its JSON byte representation.
+// It behaves exactly like Encode, but returns the encoded bytes without converting them into a string.
+func (JSONCodec[T]) EncodeBytes(src T) ([]byte, error) {
+	return json.Marshal(src)
+}
+
+// DecodeBytes method reconstructs a value of the original type from its JSON byte representation.
+// It behaves exactly like Decode, returning the zero value of T together with any decoding error.
+func (JSONCodec[T]) DecodeBytes(src []byte) (T, error) {
 	var entry T
 
-	if err := json.Unmarshal([]byte(src), &entry); err != nil {
+	if err := json.Unmarshal(src, &entry); err != nil {
 		return entry, err
 	}
 
 	return entry, nil
 }
+
+// encodeBytes function encodes the value with the encoder, using the byte-slice variant when it is a Codec.
+// It allows wrapping transcoders to treat any inner transcoder as a producer of bytes without extra copies.
+func encodeBytes[T any](encoder Encoder[T], src T) ([]byte, error) {
+	if codec, ok := encoder.(Codec[T]); ok {
+		return codec.EncodeBytes(src)
+	}
+
+	text, err := encoder.Encode(src)
+
+	return []byte(text), err
+}
+
+// decodeBytes function decodes the bytes with the transcoder, using the byte-slice variant when it is a Codec.
+// It allows wrapping transcoders to hand the unwrapped payload to any inner transcoder without extra copies.
+func decodeBytes[T any](transcoder Transcoder[T], src []byte) (T, error) {
+	if codec, ok := transcoder.(Codec[T]); ok {
+		return codec.DecodeBytes(src)
+	}
+
+	return transcoder.Decode(string(src))
+}
@@ -14,14 +14,14 @@ type Person struct {
 	Email string `json:"email,omitempty"`
 }
 
-// TestDefaultTranscoder is the table-driven test for the Marshal method of defaultTranscoder[T].
+// TestDefaultTranscoder is the table-driven test for the Marshal method of JSONCodec[T].
 // It ensures the method correctly converts a value of type T into its JSON byte representation while
 // fully preserving standard JSON marshalling semantics. This includes proper handling of struct field
 // tags such as omitempty, ignoring fields tagged with "-", omitting unexported fields, and correctly
 // invoking custom Encode JSON methods when present. The test also verifies that Marshal never returns
 // an error for any valid Go value.
 func TestDefaultTranscoder(t *testing.T) {
-	transcoder := &defaultTranscoder[Person]{}
+	transcoder := &JSONCodec[Person]{}
 
 	cases := []struct {
 		name     string
@@ -54,7 +54,7 @@ type CustomType struct {
 	Value string
 }
 
-// TestNewJSONTranscoder is the table-driven test for the defaultTranscoder constructor.
+// TestNewJSONTranscoder is the table-driven test for the NewJSONCodec constructor.
 // It ensures the function returns a non-nil, reusable instance for any type T,
 // verifying stateless behavior and generic instantiation.
 func TestNewJSONTranscoder(t *testing.T) {
@@ -63,10 +63,10 @@ func TestNewJSONTranscoder(t *testing.T) {
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
 			// Instantiate for int (covers generic [T any])
-			transcoder := &defaultTranscoder[int]{}
+			transcoder := NewJSONCodec[int]()
 			// Use assert.NotNil to verify the constructor returns a valid pointer.
 			// A nil result would indicate instantiation failure.
-			assert.NotNil(t, transcoder, "NewJSONTranscoder should return non-nil instance")
+			assert.NotNil(t, transcoder, "NewJSONCodec should return non-nil instance")
 
 			// Reuse the same instance for multiple calls (verifies statelessness)
 			_, _ = transcoder.Encode(42)
@@ -74,7 +74,7 @@ func TestNewJSONTranscoder(t *testing.T) {
 	}
 }
 
-// TestDefaultTranscoderDecode is the table-driven test for the Decode method of defaultTranscoder[T].
+// TestDefaultTranscoderDecode is the table-driven test for the Decode method of JSONCodec[T].
 // It verifies that the method correctly decodes JSON byte data into a new value of type T while fully
 // respecting standard JSON unmarshalling semantics. This includes struct field tags (omitempty, -),
 // custom Decode json implementations, proper handling of nil/pointer types, and JSON null values.
@@ -88,16 +88,16 @@ func TestDefaultTranscoderDecode(t *testing.T) {
 		want       any
 		wantErr    bool
 	}{
-		{name: "Valid integer", transcoder: &defaultTranscoder[int]{}, input: `123`, want: 123},
-		{name: "Valid string", transcoder: &defaultTranscoder[string]{}, input: `"xai"`, want: "xai"},
-		{name: "JSON null becomes nil pointer", transcoder: &defaultTranscoder[*string]{}, input: `null`, want: (*string)(nil)},
-		{name: "Full struct population", transcoder: &defaultTranscoder[User]{}, input: `{"id":5,"name":"Bob","age":40}`, want: User{ID: 5, Name: "Bob", Age: intPtr(40)}},
-		{name: "Partial struct population", transcoder: &defaultTranscoder[User]{}, input: `{"id":10}`, want: User{ID: 10}},
-		{name: "Custom UnmarshalJSON success", transcoder: &defaultTranscoder[CustomType]{}, input: `{"wrapped":"yes"}`, want: CustomType{}},
-		{name: "Invalid JSON syntax", transcoder: &defaultTranscoder[int]{}, input: `abc`, wantErr: true},
-		{name: "Type mismatch error", transcoder: &defaultTranscoder[int]{}, input: `"text"`, wantErr: true},
-		{name: "Custom UnmarshalJSON failure", transcoder: &defaultTranscoder[CustomType]{}, input: `{}`, want: CustomType{}, wantErr: false},
-		{name: "Malformed json error", transcoder: &defaultTranscoder[CustomType]{}, input: `{"name": "error_data", "value" 456`, want: nil, wantErr: true},
+		{name: "Valid integer", transcoder: &JSONCodec[int]{}, input: `123`, want: 123},
+		{name: "Valid string", transcoder: &JSONCodec[string]{}, input: `"xai"`, want: "xai"},
+		{name: "JSON null becomes nil pointer", transcoder: &JSONCodec[*string]{}, input: `null`, want: (*string)(nil)},
+		{name: "Full struct population", transcoder: &JSONCodec[User]{}, input: `{"id":5,"name":"Bob","age":40}`, want: User{ID: 5, Name: "Bob", Age: intPtr(40)}},
+		{name: "Partial struct population", transcoder: &JSONCodec[User]{}, input: `{"id":10}`, want: User{ID: 10}},
+		{name: "Custom UnmarshalJSON success", transcoder: &JSONCodec[CustomType]{}, input: `{"wrapped":"yes"}`, want: CustomType{}},
+		{name: "Invalid JSON syntax", transcoder: &JSONCodec[int]{}, input: `abc`, wantErr: true},
+		{name: "Type mismatch error", transcoder: &JSONCodec[int]{}, input: `"text"`, wantErr: true},
+		{name: "Custom UnmarshalJSON failure", transcoder: &JSONCodec[CustomType]{}, input: `{}`, want: CustomType{}, wantErr: false},
+		{name: "Malformed json error", transcoder: &JSONCodec[CustomType]{}, input: `{"name": "error_data", "value" 456`, want: nil, wantErr: true},
 	}
 
 	for _, tt := range cases {
@@ -106,15 +106,15 @@ func TestDefaultTranscoderDecode(t *testing.T) {
 			var err error
 
 			switch tc := tt.transcoder.(type) {
-			case *defaultTranscoder[int]:
+			case *JSONCodec[int]:
 				got, err = tc.Decode(tt.input)
-			case *defaultTranscoder[string]:
+			case *JSONCodec[string]:
 				got, err = tc.Decode(tt.input)
-			case *defaultTranscoder[*string]:
+			case *JSONCodec[*string]:
 				got, err = tc.Decode(tt.input)
-			case *defaultTranscoder[User]:
+			case *JSONCodec[User]:
 				got, err = tc.Decode(tt.input)
-			case *defaultTranscoder[CustomType]:
+			case *JSONCodec[CustomType]:
 				got, err = tc.Decode(tt.input)
 			default:
 				t.Fatalf("unhandled transcoder type: %T", tc)
@@ -131,3 +131,25 @@ func TestDefaultTranscoderDecode(t *testing.T) {
 		})
 	}
 }
+
+// TestJSONCodecBytes verifies that the byte-slice variants of JSONCodec produce the same representation
+// as their string counterparts, and that JSONCodec satisfies the Codec interface.
+func TestJSONCodecBytes(t *testing.T) {
+	var codec Codec[User] = NewJSONCodec[User]()
+
+	user := User{ID: 7, Name: "Carol", Age: intPtr(21)}
+
+	encoded, err := codec.EncodeBytes(user)
+	assert.NoError(t, err, "EncodeBytes must succeed for valid Go values")
+
+	text, err := codec.Encode(user)
+	assert.NoError(t, err, "Encode must succeed for valid Go values")
+	assert.Equal(t, text, string(encoded), "Expected both encoders to produce the same representation")
+
+	decoded, err := codec.DecodeBytes(encoded)
+	assert.NoError(t, err, "DecodeBytes must succeed for its own output")
+	assert.Equal(t, user, decoded, "Expected the round trip to preserve the value")
+
+	_, err = codec.DecodeBytes([]byte(`{"id":`))
+	assert.Error(t, err, "DecodeBytes must return an error for malformed JSON")
+}
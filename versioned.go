@@ -0,0 +1,101 @@
+package fetcher
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json"
+)
+
+// Migration type upgrades a JSON payload from the version it is registered for to the following version.
+// It receives the payload exactly as stored in the envelope and returns the payload in the next version's shape.
+type Migration func(payload json.RawMessage) (json.RawMessage, error)
+
+// envelope struct describes the JSON envelope written by the VersionedCodec.
+type envelope struct {
+	Version *int            `json:"v"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// VersionedCodec is a transcoder that stores tasks in a versioned JSON envelope of the form {"v":N,"payload":...}.
+// Payloads of older versions are upgraded one version at a time by the registered migrations before they are
+// decoded into T, so task structs can evolve while old payloads are still waiting in the lists. Payloads without
+// an envelope are treated as version 0, which lets plain JSON lists be migrated to the envelope format.
+// Payloads of newer or unsupported versions are reported as decode failures with ErrUnknownVersion.
+type VersionedCodec[T any] struct {
+	version    int
+	migrations map[int]Migration
+}
+
+// NewVersionedCodec function returns a VersionedCodec producing envelopes of the given current version.
+// The migrations map every version to the function upgrading its payloads to the next version,
+// so payloads of version N can be decoded when migrations are registered for every version from N to the current one.
+func NewVersionedCodec[T any](version int, migrations map[int]Migration) *VersionedCodec[T] {
+	registry := make(map[int]Migration, len(migrations))
+
+	for from, migration := range migrations {
+		registry[from] = migration
+	}
+
+	return &VersionedCodec[T]{version: version, migrations: registry}
+}
+
+// Encode method wraps the JSON representation of the value in an envelope of the current version.
+func (c *VersionedCodec[T]) Encode(src T) (string, error) {
+	bytes, err := c.EncodeBytes(src)
+
+	return string(bytes), err
+}
+
+// Decode method migrates the enveloped payload to the current version and decodes it into a value of type T.
+func (c *VersionedCodec[T]) Decode(src string) (T, error) {
+	return c.DecodeBytes([]byte(src))
+}
+
+// EncodeBytes method wraps the JSON representation of the value in an envelope of the current version.
+func (c *VersionedCodec[T]) EncodeBytes(src T) ([]byte, error) {
+	payload, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+
+	version := c.version
+
+	return json.Marshal(envelope{Version: &version, Payload: payload})
+}
+
+// DecodeBytes method migrates the enveloped payload to the current version and decodes it into a value of type T.
+// It returns ErrUnknownVersion when the payload is newer than the current version or a migration is missing,
+// and the error of the failing migration when an upgrade step fails.
+func (c *VersionedCodec[T]) DecodeBytes(src []byte) (T, error) {
+	var entry T
+	var wrapped envelope
+
+	// Anything that is not an envelope, including JSON values other than objects, is a version 0 payload.
+	// Both fields must be present, so legacy payloads that happen to carry a v field of their own stay version 0.
+	version, payload := 0, json.RawMessage(src)
+	if err := json.Unmarshal(src, &wrapped); err == nil && wrapped.Version != nil && len(wrapped.Payload) > 0 {
+		version, payload = *wrapped.Version, wrapped.Payload
+	}
+
+	if version > c.version {
+		return entry, fmt.Errorf("%w: %d is newer than %d", ErrUnknownVersion, version, c.version)
+	}
+
+	for ; version < c.version; version++ {
+		migration, ok := c.migrations[version]
+		if !ok {
+			return entry, fmt.Errorf("%w: no migration from %d", ErrUnknownVersion, version)
+		}
+
+		var err error
+		if payload, err = migration(payload); err != nil {
+			return entry, fmt.Errorf("migrate payload from version %d: %w", version, err)
+		}
+	}
+
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
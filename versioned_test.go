@@ -0,0 +1,114 @@
+package fetcher
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+// TaskV2 is the current shape of a task whose title field was called name before version 2.
+type TaskV2 struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// TestVersionedCodec is the table-driven test for the decoding of VersionedCodec[T].
+// Version 0 payloads carry the identifier as a string, which version 1 turned into a number,
+// and version 2 renamed the name field to title.
+func TestVersionedCodec(t *testing.T) {
+	migrations := map[int]Migration{
+		0: func(payload json.RawMessage) (json.RawMessage, error) {
+			var old struct {
+				ID   json.Number `json:"id"`
+				Name string      `json:"name"`
+			}
+
+			if err := json.Unmarshal(payload, &old); err != nil {
+				return nil, err
+			}
+
+			id, err := old.ID.Int64()
+			if err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(map[string]interface{}{"id": id, "name": old.Name})
+		},
+		1: func(payload json.RawMessage) (json.RawMessage, error) {
+			var old struct {
+				ID   int    `json:"id"`
+				Name string `json:"name"`
+			}
+
+			if err := json.Unmarshal(payload, &old); err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(TaskV2{ID: old.ID, Title: old.Name})
+		},
+	}
+
+	codec := NewVersionedCodec[TaskV2](2, migrations)
+
+	cases := []struct {
+		name    string
+		input   string
+		want    TaskV2
+		wantErr error
+	}{
+		{name: "Current version", input: `{"v":2,"payload":{"id":1,"title":"a"}}`, want: TaskV2{ID: 1, Title: "a"}},
+		{name: "Previous version", input: `{"v":1,"payload":{"id":2,"name":"b"}}`, want: TaskV2{ID: 2, Title: "b"}},
+		{name: "Enveloped version 0", input: `{"v":0,"payload":{"id":"3","name":"c"}}`, want: TaskV2{ID: 3, Title: "c"}},
+		{name: "Plain legacy payload", input: `{"id":"4","name":"d"}`, want: TaskV2{ID: 4, Title: "d"}},
+		{name: "Newer version", input: `{"v":3,"payload":{"id":5}}`, wantErr: ErrUnknownVersion},
+		{name: "Unsupported old version", input: `{"v":-1,"payload":{"id":6}}`, wantErr: ErrUnknownVersion},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := codec.Decode(tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr, "Unexpected decode error")
+				return
+			}
+
+			assert.NoError(t, err, "Decode must succeed for supported versions")
+			assert.Equal(t, tt.want, got, "Unexpected migrated task")
+		})
+	}
+
+	// FailingMigration verifies that errors of migrations are reported as decode errors.
+	t.Run("FailingMigration", func(t *testing.T) {
+		_, err := codec.Decode(`{"v":0,"payload":{"id":"not a number"}}`)
+		assert.Error(t, err, "Expected the migration error to be reported")
+		assert.False(t, errors.Is(err, ErrUnknownVersion), "Expected the migration error rather than an unknown version")
+	})
+
+	// LegacyVersionField verifies that a legacy payload with a v field of its own is not mistaken for an envelope.
+	t.Run("LegacyVersionField", func(t *testing.T) {
+		type LegacyJob struct {
+			V    int    `json:"v"`
+			Name string `json:"name"`
+		}
+
+		identity := func(payload json.RawMessage) (json.RawMessage, error) { return payload, nil }
+		legacy := NewVersionedCodec[LegacyJob](1, map[int]Migration{0: identity})
+
+		decoded, err := legacy.Decode(`{"v":7,"name":"resize"}`)
+		assert.NoError(t, err, "Decode must treat the legacy payload as version 0")
+		assert.Equal(t, LegacyJob{V: 7, Name: "resize"}, decoded, "Expected the legacy v field to be preserved")
+	})
+
+	// RoundTrip verifies that encoded values are wrapped in an envelope of the current version.
+	t.Run("RoundTrip", func(t *testing.T) {
+		encoded, err := codec.Encode(TaskV2{ID: 7, Title: "g"})
+		assert.NoError(t, err, "Encode must succeed for valid Go values")
+		assert.JSONEq(t, `{"v":2,"payload":{"id":7,"title":"g"}}`, encoded, "Unexpected envelope")
+
+		decoded, err := codec.Decode(encoded)
+		assert.NoError(t, err, "Decode must succeed for its own output")
+		assert.Equal(t, TaskV2{ID: 7, Title: "g"}, decoded, "Expected the round trip to preserve the value")
+	})
+}